@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/gomake/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add Docker, a license or a feature module to an existing project",
+	Long: `Add parts of a generated project to an existing one. The module path is
+read from go.mod and the architecture is detected from the directory layout.
+Only new files are written; existing files that would change are reported
+as conflicts and left untouched.`,
+
+	Annotations: map[string]string{architecturesAnnotation: ""},
+}
+
+var addDockerCmd = &cobra.Command{
+	Use:   "docker",
+	Short: "Add a Dockerfile, docker-compose.yml and .dockerignore",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAdd(cmd, func(adder *generator.Adder) error {
+			return adder.AddDocker()
+		})
+	},
+}
+
+var addLicenseCmd = &cobra.Command{
+	Use:     "license <MIT|Apache|BSD|GPL>",
+	Short:   "Add a LICENSE file",
+	Example: "  gomake add license Apache",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAdd(cmd, func(adder *generator.Adder) error {
+			return adder.AddLicense(args[0])
+		})
+	},
+}
+
+var addModuleCmd = &cobra.Command{
+	Use:     "module <feature>",
+	Short:   "Add a feature module",
+	Example: "  gomake add module postgres",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var config *generator.Config
+		var added []*generator.FeatureModule
+		err := runAdd(cmd, func(adder *generator.Adder) error {
+			var err error
+			config = adder.Config()
+			added, err = adder.AddFeature(args[0])
+			return err
+		})
+		if len(added) > 0 {
+			printFeatureSteps(config, added)
+		}
+		return err
+	},
+}
+
+var (
+	addDir  string
+	addArch string
+)
+
+func init() {
+	addCmd.AddCommand(addDockerCmd)
+	addCmd.AddCommand(addLicenseCmd)
+	addCmd.AddCommand(addModuleCmd)
+	rootCmd.AddCommand(addCmd)
+
+	addCmd.PersistentFlags().StringVarP(&addDir, "dir", "d", ".",
+		"Project directory")
+	addCmd.PersistentFlags().StringVarP(&addArch, "arch", "a", "",
+		"Architecture of the project (default: detected)")
+	addModuleCmd.Long = fmt.Sprintf("Add a feature module (%s)", strings.Join(generator.FeatureNames(), ", "))
+}
+
+// runAdd detects the project in addDir and applies add through a writer
+// that never overwrites existing files
+func runAdd(cmd *cobra.Command, add func(adder *generator.Adder) error) error {
+	cmd.SilenceUsage = true
+
+	config, err := generator.DetectProject(addDir, addArch)
+	if err != nil {
+		return err
+	}
+	log.Info("Detected project", "module", config.ModulePath, "architecture", config.Architecture)
+
+	writer := generator.NewNoClobberWriter(generator.NewDiskWriter())
+	adder, err := generator.NewAdder(config, addDir, log, writer)
+	if err != nil {
+		return err
+	}
+
+	if err := add(adder); err != nil {
+		return err
+	}
+	if err := adder.UpdateManifest(); err != nil {
+		return err
+	}
+
+	return printWriteResults(addDir, writer)
+}
+
+// printWriteResults lists the files handled by writer relative to
+// projectPath and fails when existing files were left untouched
+func printWriteResults(projectPath string, writer *generator.NoClobberWriter) error {
+	for _, result := range writer.Results() {
+		rel, err := filepath.Rel(projectPath, result.Path)
+		if err != nil {
+			rel = result.Path
+		}
+		switch result.Status {
+		case generator.WriteCreated:
+			color.Green("  + %s", rel)
+		case generator.WriteUnchanged:
+			fmt.Printf("  = %s (unchanged)\n", rel)
+		case generator.WriteConflict:
+			color.Yellow("  ! %s (exists with different content, not overwritten)", rel)
+		}
+	}
+
+	if conflicts := writer.Conflicts(); len(conflicts) > 0 {
+		return fmt.Errorf("%d existing file(s) differ from the generated version and were left untouched", len(conflicts))
+	}
+	return nil
+}
+
+// printFeatureSteps lists the changes of added modules to existing files
+func printFeatureSteps(config *generator.Config, modules []*generator.FeatureModule) {
+	var requires []string
+	var env []generator.EnvVar
+	var services []string
+	var targets []generator.MakeTarget
+	for _, module := range modules {
+		for _, require := range module.GoRequires {
+			requires = append(requires, strings.Replace(require, " ", "@", 1))
+		}
+		if module.Env != nil {
+			env = append(env, module.Env(config)...)
+		}
+		if module.Services != nil {
+			for _, service := range module.Services(config) {
+				services = append(services, service.Name)
+			}
+		}
+		targets = append(targets, module.MakeTargets...)
+	}
+
+	if len(requires)+len(env)+len(services)+len(targets) == 0 {
+		return
+	}
+
+	color.Cyan("\n📝 Update existing files by hand:")
+	if len(requires) > 0 {
+		fmt.Printf("   go get %s\n", strings.Join(requires, " "))
+	}
+	if len(env) > 0 {
+		fmt.Println("   .env:")
+		for _, v := range env {
+			fmt.Printf("     %s=%s\n", v.Key, v.Value)
+		}
+	}
+	if len(services) > 0 {
+		fmt.Printf("   docker-compose.yml services: %s\n", strings.Join(services, ", "))
+	}
+	if len(targets) > 0 {
+		fmt.Println("   Makefile targets:")
+		for _, target := range targets {
+			fmt.Printf("     %s: ## %s\n", target.Name, target.Help)
+			for _, command := range target.Commands {
+				fmt.Printf("     \t%s\n", command)
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gomake/internal/generator"
+	"github.com/gomake/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Generate every project listed in a spec file",
+	Long: `Generate many projects at once from a spec file listing each project's name,
+module, architecture, feature modules, license and template variables.
+Defaults apply to every project that doesn't set a value:
+
+  defaults:
+    module_prefix: github.com/acme
+    architecture: hexagonal
+    with: [postgres]
+  projects:
+    - name: orders
+      with: [postgres, redis]
+      variables: {team: payments}
+    - name: billing
+      architecture: clean
+      license: Apache
+      dir: services
+
+Every project is checked before any is generated. Projects are generated in
+parallel, each one staged and moved into place only once complete, and a
+report lists the outcome of each.`,
+	Example: `  gomake apply -f services.yml
+  gomake apply -f services.yml -d monorepo --jobs 8 --fail-fast`,
+	Args: cobra.NoArgs,
+	RunE: runApply,
+
+	Annotations: map[string]string{architecturesAnnotation: ""},
+}
+
+var (
+	applyFile         string
+	applyDir          string
+	applyJobs         int
+	applyFailFast     bool
+	applySkipExisting bool
+)
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().StringVarP(&applyFile, "file", "f", "",
+		"Spec file listing the projects")
+	applyCmd.Flags().StringVarP(&applyDir, "dir", "d", ".",
+		"Target directory for the projects")
+	applyCmd.Flags().IntVarP(&applyJobs, "jobs", "j", 4,
+		"Number of projects generated at the same time")
+	applyCmd.Flags().BoolVar(&applyFailFast, "fail-fast", false,
+		"Stop at the first failure, rolling back the projects being generated")
+	applyCmd.Flags().BoolVar(&applySkipExisting, "skip-existing", false,
+		"Skip projects whose directory already exists instead of failing")
+	_ = applyCmd.MarkFlagRequired("file")
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	spec, err := generator.LoadBatchSpec(applyFile)
+	if err != nil {
+		return err
+	}
+
+	configFile, err := generator.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	configs, err := spec.Configs(applyDir, configFile)
+	if err != nil {
+		return err
+	}
+
+	// Check everything the generator doesn't before generating anything
+	var pending, existing []*generator.Config
+	var problems []string
+	for _, config := range configs {
+		if err := validateProjectName(config.ProjectName); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", config.ProjectName, err))
+			continue
+		}
+		if config.ModulePath != "" {
+			if err := validateModulePath(config.ModulePath); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", config.ProjectName, err))
+				continue
+			}
+		}
+
+		inUse, err := projectDirInUse(filepath.Join(config.TargetDir, config.ProjectName))
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("%s: %v", config.ProjectName, err))
+		case inUse && applySkipExisting:
+			existing = append(existing, config)
+		case inUse:
+			problems = append(problems, fmt.Sprintf("%s: project directory already exists: %s (use --skip-existing to leave it)",
+				config.ProjectName, filepath.Join(config.TargetDir, config.ProjectName)))
+		default:
+			pending = append(pending, config)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid spec:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	log.Info("Generating projects", "projects", len(pending), "jobs", applyJobs)
+
+	// Ctrl-C stops the batch and rolls back the projects being generated
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	results := generator.RunBatch(ctx, pending, generator.BatchOptions{
+		Jobs:     applyJobs,
+		FailFast: applyFailFast,
+	}, generateBatchProject)
+	stop()
+
+	return printBatchReport(results, existing)
+}
+
+// generateBatchProject generates one project of a batch. Projects log
+// nothing, as their output would interleave; the report covers them.
+func generateBatchProject(ctx context.Context, config *generator.Config) error {
+	quiet := logger.New(false)
+	quiet.SetOutput(io.Discard)
+
+	gen, err := generator.New(config, quiet)
+	if err != nil {
+		return fmt.Errorf("failed to create generator: %w", err)
+	}
+	return gen.GenerateContext(ctx)
+}
+
+// printBatchReport lists the outcome of every project and fails when any
+// was not generated
+func printBatchReport(results []generator.BatchResult, existing []*generator.Config) error {
+	width := 0
+	for _, result := range results {
+		width = max(width, len(result.Config.ProjectName))
+	}
+	for _, config := range existing {
+		width = max(width, len(config.ProjectName))
+	}
+
+	counts := make(map[generator.BatchStatus]int)
+	fmt.Println()
+	for _, result := range results {
+		counts[result.Status]++
+		name := fmt.Sprintf("%-*s", width, result.Config.ProjectName)
+		switch result.Status {
+		case generator.BatchGenerated:
+			color.Green("  ✓ %s  %s (%s)", name, filepath.Join(result.Config.TargetDir, result.Config.ProjectName),
+				result.Duration.Round(time.Millisecond))
+		case generator.BatchFailed:
+			color.Red("  ✗ %s  %v", name, result.Err)
+		case generator.BatchCancelled:
+			color.Yellow("  - %s  cancelled", name)
+		}
+	}
+	for _, config := range existing {
+		fmt.Printf("  = %-*s  %s exists, skipped\n", width, config.ProjectName, filepath.Join(config.TargetDir, config.ProjectName))
+	}
+
+	fmt.Printf("\n%d generated, %d failed, %d cancelled, %d skipped\n",
+		counts[generator.BatchGenerated], counts[generator.BatchFailed], counts[generator.BatchCancelled], len(existing))
+
+	if notGenerated := counts[generator.BatchFailed] + counts[generator.BatchCancelled]; notGenerated > 0 {
+		return fmt.Errorf("%d of %d project(s) were not generated", notGenerated, len(results))
+	}
+	return nil
+}
@@ -3,7 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/gomake/internal/generator"
@@ -30,9 +30,14 @@ var configShowCmd = &cobra.Command{
 	RunE:  runConfigShow,
 }
 
+var showResolved bool
+
 func init() {
 	configCmd.AddCommand(configInitCmd)
 	configCmd.AddCommand(configShowCmd)
+
+	configShowCmd.Flags().BoolVar(&showResolved, "resolved", false,
+		"Show where each effective default comes from")
 	rootCmd.AddCommand(configCmd)
 }
 
@@ -74,11 +79,19 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 
 	// Show defaults
 	color.Yellow("🔧 Defaults:")
-	fmt.Printf("  Architecture: %s\n", config.Defaults.Architecture)
-	fmt.Printf("  License: %s\n", config.Defaults.License)
-	fmt.Printf("  Docker: %v\n", config.Defaults.WithDocker)
-	fmt.Printf("  Makefile: %v\n", config.Defaults.WithMakefile)
-	fmt.Printf("  Git: %v\n", config.Defaults.WithGit)
+	if showResolved {
+		for _, key := range generator.DefaultKeys {
+			fmt.Printf("  %s: %s (from %s)\n", key, config.Defaults.Value(key), config.DefaultSources[key])
+		}
+	} else {
+		fmt.Printf("  Architecture: %s\n", config.Defaults.Architecture)
+		fmt.Printf("  License: %s\n", config.Defaults.License)
+		fmt.Printf("  Docker: %v\n", config.Defaults.WithDocker)
+		fmt.Printf("  Makefile: %v\n", config.Defaults.WithMakefile)
+		fmt.Printf("  Git: %v\n", config.Defaults.WithGit)
+		fmt.Printf("  Module prefix: %s\n", config.Defaults.ModulePrefix)
+		fmt.Printf("  Features: %s\n", strings.Join(config.Defaults.Features, ", "))
+	}
 
 	// Show custom templates
 	if len(config.Templates) > 0 {
@@ -95,14 +108,7 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 	// Show config file locations
 	fmt.Printf("\n")
 	color.Yellow("📁 Config file locations (in order of precedence):")
-	configPaths := []string{
-		".gomake.yml",
-		".gomake.yaml",
-		filepath.Join(os.Getenv("HOME"), ".gomake.yml"),
-		filepath.Join(os.Getenv("HOME"), ".config", "gomake", "config.yml"),
-	}
-
-	for _, path := range configPaths {
+	for _, path := range generator.ConfigPaths() {
 		if _, err := os.Stat(path); err == nil {
 			color.Green("  ✓ %s (found)", path)
 		} else {
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/gomake/internal/diff"
+	"github.com/gomake/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare a project against what gomake would generate now",
+	Long: `Render the project in memory with the current templates and print a unified
+diff against the files on disk. Lines starting with - are what gomake
+generates, lines starting with + are local changes. Generated files missing
+on disk are shown as deleted; files gomake doesn't generate are ignored.
+
+The project is rendered from its manifest (.gomake/manifest.yml), or from
+the detected layout when it has none; the flags override either. The
+command exits non-zero when the project has drifted, e.g. to catch services
+diverging from the team template in CI.`,
+	Example: `  gomake diff
+  gomake diff --stat -d services/orders
+  gomake diff --name-only --with-docker`,
+	Args: cobra.NoArgs,
+	RunE: runDiff,
+
+	Annotations: map[string]string{architecturesAnnotation: ""},
+}
+
+var (
+	diffDir        string
+	diffStat       bool
+	diffNameOnly   bool
+	diffArch       string
+	diffLicense    string
+	diffTemplate   string
+	diffFeatures   []string
+	diffWithDocker bool
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVarP(&diffDir, "dir", "d", ".",
+		"Project directory")
+	diffCmd.Flags().BoolVar(&diffStat, "stat", false,
+		"Show the number of changed lines per file instead of the diff")
+	diffCmd.Flags().BoolVar(&diffNameOnly, "name-only", false,
+		"Show only the names of the files that differ")
+	diffCmd.Flags().StringVarP(&diffArch, "arch", "a", "",
+		"Architecture of the project (default: recorded or detected)")
+	diffCmd.Flags().StringVarP(&diffLicense, "license", "l", "",
+		"License type (default: recorded or detected)")
+	diffCmd.Flags().StringVarP(&diffTemplate, "template", "t", "",
+		"Custom template from .gomake.yml (default: recorded)")
+	diffCmd.Flags().StringSliceVar(&diffFeatures, "with", nil,
+		"Feature modules, comma separated (default: recorded)")
+	diffCmd.Flags().BoolVar(&diffWithDocker, "with-docker", false,
+		"Compare the Docker files too (default: recorded)")
+	diffCmd.MarkFlagsMutuallyExclusive("stat", "name-only")
+}
+
+// fileDiff is a generated file that differs from the one on disk
+type fileDiff struct {
+	path      string
+	generated string
+	current   string
+	missing   bool
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	config, err := generator.DetectProject(diffDir, diffArch)
+	if err != nil {
+		return err
+	}
+	flags := cmd.Flags()
+	if flags.Changed("license") {
+		config.License = diffLicense
+	}
+	if flags.Changed("with") {
+		config.Features = diffFeatures
+	}
+	if flags.Changed("with-docker") {
+		config.WithDocker = diffWithDocker
+	}
+	if err := loadProjectTemplate(config, diffDir, diffTemplate); err != nil {
+		return err
+	}
+	log.Debug("Detected project", "module", config.ModulePath, "architecture", config.Architecture)
+	cmd.SilenceUsage = true
+
+	changes, err := generator.CompareProject(config, diffDir)
+	if err != nil {
+		return err
+	}
+
+	var drifted []fileDiff
+	for _, change := range changes {
+		if change.Status == generator.FileUnchanged {
+			continue
+		}
+		rel, err := filepath.Rel(diffDir, change.File.Path)
+		if err != nil {
+			rel = change.File.Path
+		}
+		drifted = append(drifted, fileDiff{
+			path:      filepath.ToSlash(rel),
+			generated: string(change.File.Data),
+			current:   string(change.Existing),
+			missing:   change.Status == generator.FileAdded,
+		})
+	}
+
+	switch {
+	case diffNameOnly:
+		for _, file := range drifted {
+			fmt.Println(file.path)
+		}
+	case diffStat:
+		printDiffStat(drifted)
+	default:
+		for _, file := range drifted {
+			printUnifiedDiff(file)
+		}
+	}
+
+	if len(drifted) > 0 {
+		return fmt.Errorf("%d of %d generated file(s) differ from the project", len(drifted), len(changes))
+	}
+	if !diffNameOnly {
+		log.Success("Project matches what gomake generates", "files", len(changes))
+	}
+	return nil
+}
+
+// printUnifiedDiff prints the diff of a file from its generated content to
+// the one on disk, colored when writing to a terminal
+func printUnifiedDiff(file fileDiff) {
+	to := "b/" + file.path
+	if file.missing {
+		to = "/dev/null"
+	}
+
+	text := diff.Unified("a/"+file.path, to, file.generated, file.current, 3)
+	for _, line := range diff.Lines(text) {
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			color.New(color.Bold).Println(line)
+		case strings.HasPrefix(line, "@@"):
+			color.Cyan(line)
+		case strings.HasPrefix(line, "-"):
+			color.Red(line)
+		case strings.HasPrefix(line, "+"):
+			color.Green(line)
+		default:
+			fmt.Println(line)
+		}
+	}
+}
+
+// printDiffStat prints the number of changed lines per file like git diff
+// --stat, with a histogram scaled to fit
+func printDiffStat(files []fileDiff) {
+	const barWidth = 40
+
+	width, most, countWidth := 0, 0, 1
+	insertions := make([]int, len(files))
+	deletions := make([]int, len(files))
+	totalIns, totalDel := 0, 0
+	for i, file := range files {
+		insertions[i], deletions[i] = diff.Stat(file.generated, file.current)
+		totalIns += insertions[i]
+		totalDel += deletions[i]
+		if len(file.path) > width {
+			width = len(file.path)
+		}
+		if n := insertions[i] + deletions[i]; n > most {
+			most = n
+			countWidth = len(fmt.Sprint(n))
+		}
+	}
+
+	for i, file := range files {
+		plus, minus := insertions[i], deletions[i]
+		if most > barWidth {
+			plus = (plus*barWidth + most - 1) / most
+			minus = (minus*barWidth + most - 1) / most
+		}
+		fmt.Printf(" %-*s | %*d %s%s\n", width, file.path, countWidth, insertions[i]+deletions[i],
+			color.GreenString(strings.Repeat("+", plus)), color.RedString(strings.Repeat("-", minus)))
+	}
+
+	if len(files) > 0 {
+		fmt.Printf(" %d file(s) changed, %d insertion(s)(+), %d deletion(s)(-)\n", len(files), totalIns, totalDel)
+	}
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/gomake/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var generateCmd = &cobra.Command{
+	Use:     "generate",
+	Aliases: []string{"g"},
+	Short:   "Scaffold entities, handlers, services and repositories in an existing project",
+	Long: `Scaffold the pieces of an entity following the conventions of the project's
+architecture, which is detected from the directory layout. An entity includes
+its repository, service and HTTP handler along with table-driven tests.
+Existing files are never overwritten.`,
+
+	Annotations: map[string]string{architecturesAnnotation: ""},
+}
+
+var (
+	generateFields string
+	generateDir    string
+	generateArch   string
+)
+
+func init() {
+	for _, kind := range generator.ComponentKinds {
+		generateCmd.AddCommand(newGenerateComponentCmd(kind))
+	}
+	rootCmd.AddCommand(generateCmd)
+
+	generateCmd.PersistentFlags().StringVarP(&generateFields, "fields", "f", "",
+		"Entity fields as name:type pairs, e.g. name:string,age:int")
+	generateCmd.PersistentFlags().StringVarP(&generateDir, "dir", "d", ".",
+		"Project directory")
+	generateCmd.PersistentFlags().StringVarP(&generateArch, "arch", "a", "",
+		"Architecture of the project (default: detected)")
+}
+
+// newGenerateComponentCmd creates the subcommand scaffolding kind
+func newGenerateComponentCmd(kind generator.ComponentKind) *cobra.Command {
+	short := fmt.Sprintf("Generate a %s", kind)
+	if kind == generator.ComponentEntity {
+		short = "Generate an entity with its repository, service and handler"
+	}
+
+	return &cobra.Command{
+		Use:     fmt.Sprintf("%s <Name>", kind),
+		Short:   short,
+		Example: fmt.Sprintf("  gomake generate %s User --fields name:string,email:string,age:int", kind),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGenerate(kind, args[0])
+		},
+	}
+}
+
+// runGenerate scaffolds a component in generateDir
+func runGenerate(kind generator.ComponentKind, name string) error {
+	component, err := generator.NewComponent(name, generateFields)
+	if err != nil {
+		return err
+	}
+
+	config, err := generator.DetectProject(generateDir, generateArch)
+	if err != nil {
+		return err
+	}
+	log.Info("Detected project", "module", config.ModulePath, "architecture", config.Architecture)
+
+	// Render in memory first so that a conflict leaves the project
+	// untouched instead of half scaffolded
+	preflight := generator.NewNoClobberWriter(generator.NewMemoryWriter())
+	cg, err := generator.NewComponentGenerator(config, generateDir, log, preflight)
+	if err != nil {
+		return err
+	}
+	if err := cg.Generate(kind, component); err != nil {
+		return err
+	}
+	if len(preflight.Conflicts()) > 0 {
+		return printWriteResults(generateDir, preflight)
+	}
+
+	writer := generator.NewNoClobberWriter(generator.NewDiskWriter())
+	cg, err = generator.NewComponentGenerator(config, generateDir, log, writer)
+	if err != nil {
+		return err
+	}
+	if err := cg.Generate(kind, component); err != nil {
+		return err
+	}
+	if err := cg.UpdateManifest(); err != nil {
+		return err
+	}
+	if err := printWriteResults(generateDir, writer); err != nil {
+		return err
+	}
+
+	wiring, err := cg.Wiring(component)
+	if err != nil {
+		return err
+	}
+	if wiring != "" {
+		color.Cyan("\n📝 Wire %s into your router:", component.Name)
+		for _, line := range strings.Split(wiring, "\n") {
+			fmt.Printf("   %s\n", line)
+		}
+	}
+	return nil
+}
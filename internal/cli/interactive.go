@@ -3,11 +3,13 @@ package cli
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/gomake/internal/generator"
 )
 
 func runInteractiveMode(projectName *string) error {
@@ -37,23 +39,24 @@ func runInteractiveMode(projectName *string) error {
 	}
 
 	// Architecture selection
+	archs := generator.Architectures()
 	color.Yellow("\n // Select Architecture:")
-	for i, arch := range availableArchs {
-		fmt.Printf("   %d) %s\n", i+1, arch)
+	for i, arch := range archs {
+		fmt.Printf("   %d) %s - %s\n", i+1, arch.Name, arch.Description)
 	}
 
-	fmt.Print("Enter choice (1-4): ")
+	fmt.Printf("Enter choice (1-%d): ", len(archs))
 	choice, err := reader.ReadString('\n')
 	if err != nil {
 		return err
 	}
 
 	choiceNum, err := strconv.Atoi(strings.TrimSpace(choice))
-	if err != nil || choiceNum < 1 || choiceNum > len(availableArchs) {
+	if err != nil || choiceNum < 1 || choiceNum > len(archs) {
 		color.Red("Invalid choice, using 'basic'")
 		architecture = "basic"
 	} else {
-		architecture = availableArchs[choiceNum-1]
+		architecture = archs[choiceNum-1].Name
 	}
 
 	// Additional options
@@ -103,3 +106,55 @@ func askConfirmation(reader *bufio.Reader, question string) bool {
 	response = strings.ToLower(strings.TrimSpace(response))
 	return response == "y" || response == "yes"
 }
+
+// resolvePrompts collects the variables requested by the selected
+// architecture. Values passed with --set win; with --yes the prompt
+// defaults are used without asking. Prompts go to stderr, so they never
+// end up in an archive streamed to stdout.
+func resolvePrompts() (map[string]string, error) {
+	vars := make(map[string]string)
+	for name, value := range variables {
+		vars[name] = value
+	}
+
+	spec, ok := generator.LookupArchitecture(architecture)
+	if !ok {
+		return vars, nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for _, prompt := range spec.Prompts {
+		if _, set := vars[prompt.Name]; set {
+			continue
+		}
+
+		if autoYes {
+			vars[prompt.Name] = prompt.Default
+			continue
+		}
+
+		message := prompt.Message
+		if message == "" {
+			message = prompt.Name
+		}
+		if prompt.Default != "" {
+			fmt.Fprintf(color.Error, "%s [%s]: ", message, prompt.Default)
+		} else {
+			fmt.Fprintf(color.Error, "%s: ", message)
+		}
+
+		// Without input, fall back to the default
+		answer, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read %s: %w", prompt.Name, err)
+		}
+
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			answer = prompt.Default
+		}
+		vars[prompt.Name] = answer
+	}
+
+	return vars, nil
+}
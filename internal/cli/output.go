@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/gomake/internal/generator"
+)
+
+// runArchiveOutput generates the project into an archive at path, or into
+// a tar.gz written to stdout when path is "-"
+func runArchiveOutput(config *generator.Config, path string, stdout io.Writer) error {
+	format := generator.ArchiveTarGz
+	if path != "-" {
+		var err error
+		if format, err = generator.ArchiveFormatFromPath(path); err != nil {
+			return err
+		}
+	}
+
+	// Generate into memory first so a failure leaves no partial archive
+	writer := generator.NewArchiveWriter(format, config.TargetDir)
+	gen, err := generator.NewWithWriter(config, log, writer)
+	if err != nil {
+		return fmt.Errorf("failed to create generator: %w", err)
+	}
+
+	if err := gen.Generate(); err != nil {
+		return fmt.Errorf("failed to generate project: %w", err)
+	}
+
+	if path == "-" {
+		if err := writer.WriteArchive(stdout); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		return nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	if err := writer.WriteArchive(file); err != nil {
+		file.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	color.Green("\n✅ Project '%s' written to %s", config.ProjectName, path)
+	return nil
+}
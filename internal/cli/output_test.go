@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gomake/internal/generator"
+)
+
+func TestRunArchiveOutputToStdout(t *testing.T) {
+	quietLog(t)
+
+	config := &generator.Config{ProjectName: "orders", Architecture: "basic", License: "MIT", TargetDir: t.TempDir()}
+	var stdout bytes.Buffer
+	if err := runArchiveOutput(config, "-", &stdout); err != nil {
+		t.Fatalf("runArchiveOutput failed: %v", err)
+	}
+
+	gz, err := gzip.NewReader(&stdout)
+	if err != nil {
+		t.Fatalf("stdout is not gzip: %v", err)
+	}
+	names := make(map[string]bool)
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("stdout is not a tar archive: %v", err)
+		}
+		names[header.Name] = true
+	}
+
+	for _, name := range []string{"orders/", "orders/go.mod", "orders/LICENSE", "orders/README.md"} {
+		if !names[name] {
+			t.Errorf("archive has no %s: %v", name, names)
+		}
+	}
+	if entries, _ := os.ReadDir(config.TargetDir); len(entries) != 0 {
+		t.Errorf("wrote %v to the target directory", entries)
+	}
+}
+
+func TestRunArchiveOutputFormatFromExtension(t *testing.T) {
+	quietLog(t)
+
+	tests := []struct {
+		path  string
+		magic string
+	}{
+		{"orders.tar.gz", "\x1f\x8b"},
+		{"orders.tgz", "\x1f\x8b"},
+		{"orders.zip", "PK\x03\x04"},
+		{"orders.rar", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tt.path)
+			config := &generator.Config{ProjectName: "orders", Architecture: "basic", License: "MIT", TargetDir: dir}
+
+			err := runArchiveOutput(config, path, io.Discard)
+			if tt.magic == "" {
+				if err == nil {
+					t.Error("runArchiveOutput accepted an unsupported extension")
+				}
+				if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+					t.Errorf("%s was created", tt.path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runArchiveOutput failed: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(data, []byte(tt.magic)) {
+				t.Errorf("%s starts with %q, want %q", tt.path, data[:4], tt.magic)
+			}
+		})
+	}
+}
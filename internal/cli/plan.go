@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/gomake/internal/generator"
+)
+
+// planNode is a directory or file in the dry-run tree
+type planNode struct {
+	name     string
+	file     *generator.MemoryFile
+	children map[string]*planNode
+}
+
+func runDryRun(config *generator.Config, out io.Writer) error {
+	writer := generator.NewMemoryWriter()
+
+	gen, err := generator.NewWithWriter(config, log, writer)
+	if err != nil {
+		return fmt.Errorf("failed to create generator: %w", err)
+	}
+
+	if err := gen.Generate(); err != nil {
+		return fmt.Errorf("failed to generate project: %w", err)
+	}
+
+	projectPath := filepath.Join(config.TargetDir, config.ProjectName)
+	printPlan(out, config.TargetDir, projectPath, writer)
+	return nil
+}
+
+// printPlan prints the directories and files recorded by writer as a tree
+// relative to root. The original renders kept for gomake upgrade in the
+// base directory of the project are left out.
+func printPlan(out io.Writer, root, projectPath string, writer *generator.MemoryWriter) {
+	tree := &planNode{children: make(map[string]*planNode)}
+	baseDir := filepath.Join(projectPath, filepath.FromSlash(generator.ProjectBaseDir))
+
+	for _, dir := range writer.Dirs() {
+		if !isWithin(baseDir, dir) {
+			tree.add(relativePath(root, dir), nil)
+		}
+	}
+
+	var count, totalSize int
+	for _, file := range writer.Files() {
+		if isWithin(baseDir, file.Path) {
+			continue
+		}
+		tree.add(relativePath(root, file.Path), file)
+		count++
+		totalSize += len(file.Data)
+	}
+
+	fmt.Fprint(out, color.CyanString("\n📋 Dry run: nothing was written to disk\n"))
+	for _, child := range tree.sortedChildren() {
+		child.print(out, "")
+	}
+
+	fmt.Fprintf(out, "\n%d files, %s total\n", count, formatSize(totalSize))
+}
+
+func (n *planNode) add(path string, file *generator.MemoryFile) {
+	if path == "." || path == "" {
+		return
+	}
+
+	node := n
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		child, ok := node.children[part]
+		if !ok {
+			child = &planNode{name: part, children: make(map[string]*planNode)}
+			node.children[part] = child
+		}
+		node = child
+	}
+	node.file = file
+}
+
+func (n *planNode) sortedChildren() []*planNode {
+	children := make([]*planNode, 0, len(n.children))
+	for _, child := range n.children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].name < children[j].name
+	})
+	return children
+}
+
+func (n *planNode) print(out io.Writer, indent string) {
+	if n.file != nil {
+		fmt.Fprintf(out, "%s%s  %s\n", indent, n.name,
+			color.HiBlackString("(%s, %s)", formatSize(len(n.file.Data)), n.file.Source))
+		return
+	}
+
+	fmt.Fprintf(out, "%s%s/\n", indent, color.BlueString(n.name))
+	for _, child := range n.sortedChildren() {
+		child.print(out, indent+"  ")
+	}
+}
+
+func relativePath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// isWithin reports whether path is dir or inside it
+func isWithin(dir, path string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+func formatSize(size int) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.1f KB", float64(size)/1024)
+}
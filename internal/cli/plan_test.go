@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/gomake/internal/generator"
+)
+
+// noColor disables colored output for the duration of the test
+func noColor(t *testing.T) {
+	t.Helper()
+
+	previous := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = previous })
+}
+
+func TestPrintPlan(t *testing.T) {
+	noColor(t)
+
+	root := t.TempDir()
+	projectPath := filepath.Join(root, "orders")
+	writer := generator.NewMemoryWriter()
+	writer.MkdirAll(filepath.Join(projectPath, "docs"))
+	writer.WriteFile(filepath.Join(projectPath, "go.mod"), bytes.Repeat([]byte("m"), 10), 0644, "gomod")
+	writer.WriteFile(filepath.Join(projectPath, "cmd", "main.go"), bytes.Repeat([]byte("g"), 2000), 0644, "common/main")
+	writer.WriteFile(filepath.Join(projectPath, ".gomake", "manifest.yml"), bytes.Repeat([]byte("y"), 20), 0644, "manifest")
+	writer.WriteFile(filepath.Join(projectPath, ".gomake", "base", "a09a"), []byte("base"), 0644, "base")
+
+	var out bytes.Buffer
+	printPlan(&out, root, projectPath, writer)
+
+	want := `
+📋 Dry run: nothing was written to disk
+orders/
+  .gomake/
+    manifest.yml  (20 B, manifest)
+  cmd/
+    main.go  (2.0 KB, common/main)
+  docs/
+  go.mod  (10 B, gomod)
+
+3 files, 2.0 KB total
+`
+	if out.String() != want {
+		t.Errorf("printPlan() printed\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	quietLog(t)
+	noColor(t)
+
+	targetDir := t.TempDir()
+	config := &generator.Config{ProjectName: "orders", Architecture: "basic", License: "MIT", TargetDir: targetDir}
+
+	var out bytes.Buffer
+	if err := runDryRun(config, &out); err != nil {
+		t.Fatalf("runDryRun failed: %v", err)
+	}
+
+	if entries, _ := os.ReadDir(targetDir); len(entries) != 0 {
+		t.Errorf("dry run wrote %v", entries)
+	}
+	for _, want := range []string{"\norders/\n", "\n  go.mod  (", "\n  LICENSE  (", " files, "} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("plan does not contain %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), " base/\n") {
+		t.Errorf("plan lists the original renders:\n%s", out.String())
+	}
+}
@@ -1,7 +1,14 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 
 	"github.com/fatih/color"
 	"github.com/gomake/internal/generator"
@@ -19,13 +26,20 @@ var (
 	withGit      bool
 	interactive  bool
 	license      string
+	templateName string
+	modulePath   string
+	dryRun       bool
+	force        bool
+	merge        bool
+	backup       bool
+	outputPath   string
+	verifyBuild  bool
+	variables    map[string]string
+	features     []string
 	verbose      bool
 
 	// Logger instance
 	log *logger.Logger
-
-	// Available architectures
-	availableArchs = []string{"hexagonal", "clean", "mvc", "basic"}
 )
 
 var rootCmd = &cobra.Command{
@@ -39,15 +53,45 @@ var rootCmd = &cobra.Command{
 `),
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		log = logger.New(verbose)
+		if usesArchitectures(cmd) {
+			loadTemplateSources()
+		}
 	},
 }
 
+// architecturesAnnotation marks commands, and parents of commands, that
+// look up architectures. Template sources are only loaded for them, as
+// that runs git.
+const architecturesAnnotation = "architectures"
+
+// usesArchitectures reports whether cmd or a parent is marked with
+// architecturesAnnotation
+func usesArchitectures(cmd *cobra.Command) bool {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if _, ok := cmd.Annotations[architecturesAnnotation]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTemplateSources registers the architectures of the cached template
+// sources. Warnings go to stderr, which keeps archives streamed to stdout
+// intact.
+func loadTemplateSources() {
+	if err := generator.DefaultSourceManager().LoadArchitectures(); err != nil {
+		fmt.Fprintln(color.Error, color.YellowString("⚠️  Failed to load template sources: %v", err))
+	}
+}
+
 var projectCmd = &cobra.Command{
 	Use:   "project [project-name]",
 	Short: "Generate a new Go project",
 	Long:  "Generate a new Go project with the specified architecture and options",
 	Args:  cobra.ExactArgs(1),
 	RunE:  runProjectCommand,
+
+	Annotations: map[string]string{architecturesAnnotation: ""},
 }
 
 func init() {
@@ -56,7 +100,7 @@ func init() {
 
 	// Project command flags
 	projectCmd.Flags().StringVarP(&architecture, "arch", "a", "basic",
-		fmt.Sprintf("Architecture type (%v)", availableArchs))
+		architectureUsage())
 	projectCmd.Flags().BoolVarP(&autoYes, "yes", "y", false,
 		"Automatic confirmation without prompts")
 	projectCmd.Flags().StringVarP(&targetDir, "dir", "d", ".",
@@ -67,10 +111,31 @@ func init() {
 		"Add Makefile with common targets (always included by default)")
 	projectCmd.Flags().BoolVar(&withGit, "with-git", false,
 		"Initialize git repository")
+	projectCmd.Flags().StringSliceVar(&features, "with", nil,
+		featureUsage())
 	projectCmd.Flags().BoolVarP(&interactive, "interactive", "i", false,
 		"Interactive mode with step-by-step wizard")
 	projectCmd.Flags().StringVarP(&license, "license", "l", "MIT",
 		"License type (MIT, Apache, BSD, GPL)")
+	projectCmd.Flags().StringVarP(&modulePath, "module", "m", "",
+		"Go module path (default: module_prefix/name, the git remote of --dir, or the project name)")
+	projectCmd.Flags().StringVarP(&templateName, "template", "t", "",
+		"Custom template from .gomake.yml to apply")
+	projectCmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"Show the files that would be generated without writing anything")
+	projectCmd.Flags().BoolVar(&force, "force", false,
+		"Generate into an existing project directory, overwriting the files gomake generates")
+	projectCmd.Flags().BoolVar(&merge, "merge", false,
+		"Generate into an existing project directory, keeping every existing file")
+	projectCmd.Flags().BoolVar(&backup, "backup", false,
+		"Move an existing project directory to a timestamped sibling before generating")
+	projectCmd.MarkFlagsMutuallyExclusive("force", "merge", "backup")
+	projectCmd.Flags().StringToStringVar(&variables, "set", nil,
+		"Set template variables (key=value), skipping their prompts")
+	projectCmd.Flags().BoolVar(&verifyBuild, "verify", false,
+		"Run go build and go vet on the generated project (offline)")
+	projectCmd.Flags().StringVarP(&outputPath, "output", "o", "",
+		"Write the project to a .tar.gz or .zip archive instead of a directory (- for tar.gz on stdout)")
 
 	// Global flags
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false,
@@ -80,8 +145,31 @@ func init() {
 func runProjectCommand(cmd *cobra.Command, args []string) error {
 	projectName := args[0]
 
+	// Keep stdout clean when streaming an archive to it
+	if outputPath == "-" {
+		log.SetOutput(os.Stderr)
+	}
+
 	log.Info("Starting project generation", "project", projectName)
 
+	// Load configuration files
+	configFile, err := generator.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Apply configured defaults to flags not passed explicitly
+	applyConfigDefaults(cmd, configFile)
+
+	// Resolve custom template before touching the target directory
+	var customTemplate *generator.TemplateConfig
+	if templateName != "" {
+		customTemplate, err = configFile.FindTemplate(templateName)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Validate inputs
 	if err := validateInputs(projectName); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
@@ -94,34 +182,114 @@ func runProjectCommand(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Ask for the variables the architecture needs
+	vars, err := resolvePrompts()
+	if err != nil {
+		return err
+	}
+
+	// Resolve the Go module path
+	module, err := resolveModulePath(projectName, configFile)
+	if err != nil {
+		return err
+	}
+
 	// Create generator config
 	config := &generator.Config{
-		ProjectName:  projectName,
-		Architecture: architecture,
-		TargetDir:    targetDir,
-		WithDocker:   withDocker,
-		WithMakefile: withMakefile,
-		WithGit:      withGit,
-		License:      license,
-		AutoYes:      autoYes,
+		ProjectName:    projectName,
+		ModulePath:     module,
+		Variables:      vars,
+		Architecture:   architecture,
+		TargetDir:      targetDir,
+		WithDocker:     withDocker,
+		WithMakefile:   withMakefile,
+		WithGit:        withGit,
+		License:        license,
+		AutoYes:        autoYes,
+		CustomTemplate: customTemplate,
+		Features:       features,
+	}
+
+	// Dry run and archive output never write a directory, so they are
+	// verified in a temporary one first
+	if verifyBuild && (dryRun || outputPath != "") {
+		if err := verifyInTempDir(config); err != nil {
+			return err
+		}
+	}
+
+	// Dry run renders into memory and prints the plan
+	if dryRun {
+		return runDryRun(config, os.Stdout)
+	}
+
+	// Archive output streams the project instead of writing a directory
+	if outputPath != "" {
+		return runArchiveOutput(config, outputPath, os.Stdout)
+	}
+
+	// Decide what happens to an existing project directory
+	if err := checkProjectExists(projectName); err != nil {
+		return err
+	}
+
+	// --merge leaves every existing file as it is
+	var writer generator.Writer = generator.NewDiskWriter()
+	var kept *generator.NoClobberWriter
+	if merge {
+		kept = generator.NewNoClobberWriter(generator.NewDiskWriter())
+		writer = kept
 	}
 
 	// Create generator
-	gen, err := generator.New(config, log)
+	gen, err := generator.NewWithWriter(config, log, writer)
 	if err != nil {
 		return fmt.Errorf("failed to create generator: %w", err)
 	}
 
+	// Ctrl-C stops generation; a new project is then removed entirely
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Generate project
-	if err := gen.Generate(); err != nil {
+	if err := gen.GenerateContext(ctx); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("project generation interrupted")
+		}
 		return fmt.Errorf("failed to generate project: %w", err)
 	}
+	stop()
+
+	if kept != nil {
+		if conflicts := kept.Conflicts(); len(conflicts) > 0 {
+			color.Yellow("\n⚠️  Kept %d existing file(s) that differ from the generated version:", len(conflicts))
+			for _, conflict := range conflicts {
+				rel, err := filepath.Rel(filepath.Join(targetDir, projectName), conflict.Path)
+				if err != nil {
+					rel = conflict.Path
+				}
+				fmt.Printf("   %s\n", rel)
+			}
+		}
+	}
+
+	// Verify the project builds
+	if verifyBuild {
+		if err := verifyProject(filepath.Join(targetDir, projectName)); err != nil {
+			return err
+		}
+	}
 
 	// Success message
 	color.Green("\n✅ Project '%s' generated successfully!", projectName)
 	color.Cyan("📁 Location: %s/%s", targetDir, projectName)
 	color.Yellow("🚀 Next steps:")
 	fmt.Printf("   cd %s\n", projectName)
+	if config.CustomTemplate != nil {
+		if unpinned := config.CustomTemplate.UnpinnedDependencies(); len(unpinned) > 0 {
+			fmt.Printf("   go get %s@latest\n", strings.Join(unpinned, "@latest "))
+		}
+	}
 	fmt.Printf("   go mod tidy\n")
 	fmt.Printf("   make help\n")
 	fmt.Printf("   make run\n")
@@ -129,10 +297,75 @@ func runProjectCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// applyConfigDefaults sets flag values from configuration files.
+// Explicitly passed flags always win.
+func applyConfigDefaults(cmd *cobra.Command, configFile *generator.ConfigFile) {
+	flags := cmd.Flags()
+	defaults := configFile.Defaults
+
+	if !flags.Changed("arch") {
+		architecture = defaults.Architecture
+	}
+	if !flags.Changed("license") {
+		license = defaults.License
+	}
+	if !flags.Changed("with-docker") {
+		withDocker = defaults.WithDocker
+	}
+	if !flags.Changed("with-makefile") {
+		withMakefile = defaults.WithMakefile
+	}
+	if !flags.Changed("with-git") {
+		withGit = defaults.WithGit
+	}
+	if !flags.Changed("with") {
+		features = defaults.Features
+	}
+}
+
+// resolveModulePath picks the module path from the --module flag, then the
+// configured module_prefix, then the git remote of the target directory,
+// and finally the bare project name
+func resolveModulePath(projectName string, configFile *generator.ConfigFile) (string, error) {
+	module := modulePath
+	if module == "" && configFile.Defaults.ModulePrefix != "" {
+		module = strings.TrimSuffix(configFile.Defaults.ModulePrefix, "/") + "/" + projectName
+	}
+	if module == "" {
+		module = generator.DetectModulePath(targetDir, projectName)
+	}
+
+	if module == "" {
+		return projectName, nil
+	}
+
+	if err := validateModulePath(module); err != nil {
+		return "", err
+	}
+
+	log.Debug("Using module path", "module", module)
+	return module, nil
+}
+
 func Execute() error {
+	// Register user-defined architectures before parsing flags; template
+	// sources are loaded by the commands that use them
+	if err := generator.LoadArchitectureDir(generator.UserArchitecturesDir(), ""); err != nil {
+		fmt.Fprintln(color.Error, color.YellowString("⚠️  Failed to load user architectures: %v", err))
+	}
+	projectCmd.Flags().Lookup("arch").Usage = architectureUsage()
+
 	return rootCmd.Execute()
 }
 
+func architectureUsage() string {
+	return fmt.Sprintf("Architecture type (%s)", strings.Join(generator.ArchitectureNames(), ", "))
+}
+
+func featureUsage() string {
+	return fmt.Sprintf("Feature modules to add, comma separated (%s)", strings.Join(generator.FeatureNames(), ", "))
+}
+
 func boolToString(b bool) string {
 	if b {
 		return color.GreenString("Yes")
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/gomake/internal/generator"
+	"github.com/gomake/pkg/logger"
+	"github.com/spf13/pflag"
+)
+
+// quietLog replaces the CLI logger with one that discards its output for
+// the duration of the test
+func quietLog(t *testing.T) {
+	t.Helper()
+
+	previous := log
+	log = logger.New(false)
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log = previous })
+}
+
+// resetProjectFlags restores the project flags read by applyConfigDefaults
+// and the existing directory flags to their defaults and marks them as not
+// passed
+func resetProjectFlags(t *testing.T) {
+	t.Helper()
+
+	for _, name := range []string{"arch", "license", "with-docker", "with-makefile", "with-git", "with", "force", "merge", "backup"} {
+		flag := projectCmd.Flags().Lookup(name)
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			if err := slice.Replace(nil); err != nil {
+				t.Fatal(err)
+			}
+		} else if err := flag.Value.Set(flag.DefValue); err != nil {
+			t.Fatal(err)
+		}
+		flag.Changed = false
+	}
+	features = nil
+}
+
+func TestApplyConfigDefaults(t *testing.T) {
+	defaults := generator.DefaultsConfig{
+		Architecture: "clean",
+		License:      "Apache",
+		WithDocker:   true,
+		WithGit:      true,
+		Features:     []string{"postgres"},
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		arch     string
+		license  string
+		docker   bool
+		git      bool
+		features []string
+	}{
+		{
+			name:     "config defaults",
+			arch:     "clean",
+			license:  "Apache",
+			docker:   true,
+			git:      true,
+			features: []string{"postgres"},
+		},
+		{
+			name:     "flags win",
+			args:     []string{"--arch", "mvc", "-l", "BSD", "--with", "redis"},
+			arch:     "mvc",
+			license:  "BSD",
+			docker:   true,
+			git:      true,
+			features: []string{"redis"},
+		},
+		{
+			name:     "explicit false flags win",
+			args:     []string{"--with-docker=false", "--with-git=false"},
+			arch:     "clean",
+			license:  "Apache",
+			features: []string{"postgres"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetProjectFlags(t)
+			t.Cleanup(func() { resetProjectFlags(t) })
+
+			if err := projectCmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			applyConfigDefaults(projectCmd, &generator.ConfigFile{Defaults: defaults})
+
+			if architecture != tt.arch || license != tt.license {
+				t.Errorf("arch, license = %s, %s, want %s, %s", architecture, license, tt.arch, tt.license)
+			}
+			if withDocker != tt.docker || withGit != tt.git {
+				t.Errorf("with-docker, with-git = %v, %v, want %v, %v", withDocker, withGit, tt.docker, tt.git)
+			}
+			if !reflect.DeepEqual(features, tt.features) {
+				t.Errorf("with = %v, want %v", features, tt.features)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/gomake/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Inspect and manage templates",
+	Long:  "List, show, render and validate templates and manage remote template sources",
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available templates",
+	Long:  "List embedded templates, custom template files from config and templates of custom architectures",
+	Args:  cobra.NoArgs,
+	RunE:  runTemplateList,
+
+	Annotations: map[string]string{architecturesAnnotation: ""},
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:     "show <name>",
+	Short:   "Print the raw text of a template",
+	Example: "  gomake template show hexagonal/main.go",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runTemplateShow,
+
+	Annotations: map[string]string{architecturesAnnotation: ""},
+}
+
+var templateRenderCmd = &cobra.Command{
+	Use:   "render <name>",
+	Short: "Render a single template",
+	Long: `Render a single template to stdout. --set assigns template data fields
+(ProjectName, ModuleName, Architecture, License, Package, WithDocker,
+WithMakefile, WithGit); any other key becomes a variable.`,
+	Example: "  gomake template render common/config --set ProjectName=x",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runTemplateRender,
+
+	Annotations: map[string]string{architecturesAnnotation: ""},
+}
+
+var templateValidateCmd = &cobra.Command{
+	Use:     "validate <dir>",
+	Short:   "Parse every template in a directory and report errors",
+	Example: "  gomake template validate ~/.config/gomake/architectures/ddd",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runTemplateValidate,
+}
+
+var templateAddCmd = &cobra.Command{
+	Use:   "add <name> <url[@ref]>",
+	Short: "Add a git repository of architectures",
+	Long: `Clone a git repository of architectures into ~/.cache/gomake/templates
+and pin the checked out commit in ~/.config/gomake/templates.lock.
+Its architectures become available as <name>/<architecture>.`,
+	Example: "  gomake template add acme https://git.internal/acme/gomake-templates.git@v2",
+	Args:    cobra.ExactArgs(2),
+	RunE:    runTemplateAdd,
+}
+
+var templateRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Fetch pinned template sources missing from the cache",
+	Long: `Fetch every template source pinned in ~/.config/gomake/templates.lock that is
+missing from ~/.cache/gomake/templates, at its pinned commit, e.g. after
+copying the lockfile to another machine.`,
+	Args: cobra.NoArgs,
+	RunE: runTemplateRestore,
+}
+
+var (
+	forceTemplateAdd bool
+	renderValues     map[string]string
+)
+
+func init() {
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateShowCmd)
+	templateCmd.AddCommand(templateRenderCmd)
+	templateCmd.AddCommand(templateValidateCmd)
+	templateCmd.AddCommand(templateAddCmd)
+	templateCmd.AddCommand(templateRestoreCmd)
+	rootCmd.AddCommand(templateCmd)
+
+	templateRenderCmd.Flags().StringToStringVar(&renderValues, "set", nil,
+		"Set template data (key=value)")
+	templateAddCmd.Flags().BoolVarP(&forceTemplateAdd, "force", "f", false,
+		"Replace an existing source with the same name")
+}
+
+func runTemplateList(cmd *cobra.Command, args []string) error {
+	entries, err := loadTemplateEntries()
+	if err != nil {
+		return err
+	}
+
+	width := 0
+	for _, entry := range entries {
+		if len(entry.Name) > width {
+			width = len(entry.Name)
+		}
+	}
+
+	var kind generator.TemplateKind
+	for _, entry := range entries {
+		if entry.Kind != kind {
+			kind = entry.Kind
+			color.Yellow("\n%s:", kind)
+		}
+		// Mark templates shadowing the embedded ones
+		layer := ""
+		if entry.Layer != "" && entry.Layer != generator.EmbeddedLayer {
+			layer = color.MagentaString(" [%s]", entry.Layer)
+		}
+		fmt.Printf("  %-*s  %s%s\n", width, entry.Name, color.HiBlackString(entry.Source), layer)
+	}
+
+	return nil
+}
+
+func runTemplateShow(cmd *cobra.Command, args []string) error {
+	entry, err := findTemplateEntry(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(entry.Text)
+	return nil
+}
+
+func runTemplateRender(cmd *cobra.Command, args []string) error {
+	entry, err := findTemplateEntry(args[0])
+	if err != nil {
+		return err
+	}
+
+	data, err := generator.NewPreviewData(entry.Name, renderValues)
+	if err != nil {
+		return err
+	}
+
+	content, err := entry.Render(data)
+	if err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+
+	fmt.Print(content)
+	return nil
+}
+
+func runTemplateValidate(cmd *cobra.Command, args []string) error {
+	problems, err := generator.ValidateTemplateDir(args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		color.Green("✅ All templates in %s are valid", args[0])
+		return nil
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem.Error())
+	}
+	return fmt.Errorf("%d invalid template(s) in %s", len(problems), args[0])
+}
+
+func loadTemplateEntries() ([]generator.TemplateEntry, error) {
+	configFile, err := generator.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return generator.ListTemplateEntries(configFile)
+}
+
+func findTemplateEntry(name string) (*generator.TemplateEntry, error) {
+	configFile, err := generator.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return generator.FindTemplateEntry(configFile, name)
+}
+
+func runTemplateAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	url, ref := generator.ParseSourceSpec(args[1])
+
+	log.Info("Fetching template source", "name", name, "url", url)
+
+	source, err := generator.DefaultSourceManager().Add(name, url, ref, forceTemplateAdd)
+	if err != nil {
+		return fmt.Errorf("failed to add template source: %w", err)
+	}
+
+	color.Green("✅ Template source '%s' pinned at %s", source.Name, shortCommit(source.Commit))
+	fmt.Printf("   Use its architectures with: gomake project <name> --arch %s/<architecture>\n", source.Name)
+	return nil
+}
+
+func runTemplateRestore(cmd *cobra.Command, args []string) error {
+	restored, err := generator.DefaultSourceManager().Restore()
+	for _, source := range restored {
+		color.Green("✅ Template source '%s' restored at %s", source.Name, shortCommit(source.Commit))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore template sources: %w", err)
+	}
+
+	if len(restored) == 0 {
+		fmt.Println("All template sources are cached")
+	}
+	return nil
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
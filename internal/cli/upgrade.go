@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/gomake/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Merge template updates into a project generated by an earlier gomake",
+	Long: `Re-render a project with the current templates and merge the changes into it.
+The original render kept in .gomake/ is the base of a three-way merge, so
+local modifications survive: unmodified files are updated, modified files
+get the template changes merged in, and overlapping changes are marked
+with conflict markers, or written to <file>.rej with --reject.
+
+Files deleted locally stay deleted. Only projects generated with a
+manifest (.gomake/manifest.yml) can be upgraded.`,
+	Args: cobra.NoArgs,
+	RunE: runUpgrade,
+
+	Annotations: map[string]string{architecturesAnnotation: ""},
+}
+
+var (
+	upgradeDir    string
+	upgradeDryRun bool
+	upgradeReject bool
+)
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+
+	upgradeCmd.Flags().StringVarP(&upgradeDir, "dir", "d", ".",
+		"Project directory")
+	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false,
+		"Show what would change without writing anything")
+	upgradeCmd.Flags().BoolVar(&upgradeReject, "reject", false,
+		"Leave conflicting files untouched and write the template changes to <file>.rej")
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	config, err := generator.DetectProject(upgradeDir, "")
+	if err != nil {
+		return err
+	}
+
+	if err := loadProjectTemplate(config, upgradeDir, ""); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	results, err := generator.NewUpgrader(config, log).Upgrade(upgradeDir, generator.UpgradeOptions{
+		DryRun: upgradeDryRun,
+		Reject: upgradeReject,
+	})
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[generator.UpgradeStatus]int)
+	for _, result := range results {
+		counts[result.Status]++
+		switch result.Status {
+		case generator.UpgradeUpdated:
+			color.Green("  ~ %s (updated)", result.Path)
+		case generator.UpgradeAdded:
+			color.Green("  + %s (added)", result.Path)
+		case generator.UpgradeMerged:
+			color.Cyan("  ~ %s (merged with local changes)", result.Path)
+		case generator.UpgradeKept:
+			fmt.Printf("  = %s (local changes kept)\n", result.Path)
+		case generator.UpgradeSkipped:
+			fmt.Printf("  - %s (deleted locally, skipped)\n", result.Path)
+		case generator.UpgradeConflict:
+			if result.Reject != "" {
+				color.Red("  ! %s (%d conflict(s), template changes in %s)", result.Path, result.Conflicts, result.Reject)
+			} else {
+				color.Red("  ! %s (%d conflict(s) marked)", result.Path, result.Conflicts)
+			}
+		}
+	}
+
+	clean := counts[generator.UpgradeUpdated] + counts[generator.UpgradeAdded]
+	fmt.Printf("\n%d clean, %d merged, %d conflicted, %d unchanged, %d kept, %d skipped\n",
+		clean, counts[generator.UpgradeMerged], counts[generator.UpgradeConflict],
+		counts[generator.UpgradeUnchanged], counts[generator.UpgradeKept], counts[generator.UpgradeSkipped])
+
+	if upgradeDryRun {
+		color.Yellow("Dry run: nothing was written")
+	}
+	if conflicts := counts[generator.UpgradeConflict]; conflicts > 0 && !upgradeDryRun {
+		return fmt.Errorf("%d file(s) have conflicts to resolve by hand", conflicts)
+	}
+	return nil
+}
+
+// loadProjectTemplate sets the custom template of config to the one named
+// name in .gomake.yml. An empty name picks the template recorded in the
+// manifest of the project in dir, as the manifest only records its name.
+func loadProjectTemplate(config *generator.Config, dir, name string) error {
+	if name == "" {
+		manifest, err := generator.ReadProjectManifest(dir)
+		if err != nil {
+			return nil
+		}
+		name = manifest.Options.Template
+	}
+	if name == "" {
+		return nil
+	}
+
+	configFile, err := generator.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	config.CustomTemplate, err = configFile.FindTemplate(name)
+	return err
+}
@@ -6,8 +6,10 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/gomake/internal/generator"
 )
 
 var (
@@ -35,13 +37,18 @@ func validateInputs(projectName string) error {
 		return err
 	}
 
-	// Validate target directory
-	if err := validateTargetDirectory(); err != nil {
+	// Validate feature modules
+	if _, err := generator.ResolveFeatures(features); err != nil {
 		return err
 	}
 
-	// Check if project already exists
-	if err := checkProjectExists(projectName); err != nil {
+	// Dry run and archive output never touch the target directory
+	if dryRun || outputPath != "" {
+		return nil
+	}
+
+	// Validate target directory
+	if err := validateTargetDirectory(); err != nil {
 		return err
 	}
 
@@ -83,13 +90,19 @@ func validateProjectName(name string) error {
 	return nil
 }
 
+func validateModulePath(module string) error {
+	if !moduleNameRegex.MatchString(module) || strings.Contains(module, "//") {
+		return fmt.Errorf("invalid module path: %s", module)
+	}
+
+	return nil
+}
+
 func validateArchitecture() error {
-	for _, arch := range availableArchs {
-		if arch == architecture {
-			return nil
-		}
+	if _, ok := generator.LookupArchitecture(architecture); ok {
+		return nil
 	}
-	return fmt.Errorf("invalid architecture: %s. Available: %v", architecture, availableArchs)
+	return fmt.Errorf("invalid architecture: %s. Available: %v", architecture, generator.ArchitectureNames())
 }
 
 func validateTargetDirectory() error {
@@ -115,31 +128,59 @@ func validateTargetDirectory() error {
 	return nil
 }
 
+// checkProjectExists refuses to generate into an existing, non-empty
+// project directory unless --force, --merge or --backup says how to treat
+// the files in it. With --backup the directory is moved aside right away.
 func checkProjectExists(projectName string) error {
 	projectPath := filepath.Join(targetDir, projectName)
-	
-	if _, err := os.Stat(projectPath); err == nil {
-		if autoYes {
-			color.Yellow("⚠️  Project directory already exists: %s", projectPath)
-			return nil
-		}
-
-		color.Yellow("⚠️  Project directory already exists: %s", projectPath)
-		fmt.Print("Do you want to overwrite it? (y/N): ")
-		
-		var response string
-		fmt.Scanln(&response)
-		response = strings.ToLower(strings.TrimSpace(response))
-		
-		if response != "y" && response != "yes" {
-			return fmt.Errorf("project creation cancelled")
-		}
-		
-		color.Yellow("🗑️  Removing existing directory...")
-		if err := os.RemoveAll(projectPath); err != nil {
-			return fmt.Errorf("failed to remove existing directory: %w", err)
-		}
+
+	inUse, err := projectDirInUse(projectPath)
+	if err != nil || !inUse {
+		return err
 	}
 
+	switch {
+	case force:
+		color.Yellow("⚠️  Project directory already exists: %s; overwriting the files gomake generates", projectPath)
+	case merge:
+		color.Yellow("⚠️  Project directory already exists: %s; keeping existing files", projectPath)
+	case backup:
+		return backupProjectDir(projectPath)
+	default:
+		return fmt.Errorf("project directory already exists: %s. Use --force to overwrite the files gomake generates, --merge to keep existing files or --backup to move it aside", projectPath)
+	}
+
+	return nil
+}
+
+// projectDirInUse reports whether projectPath exists and isn't an empty
+// directory
+func projectDirInUse(projectPath string) (bool, error) {
+	info, err := os.Stat(projectPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s: %w", projectPath, err)
+	}
+	if !info.IsDir() {
+		return false, fmt.Errorf("project path exists and is not a directory: %s", projectPath)
+	}
+
+	entries, err := os.ReadDir(projectPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", projectPath, err)
+	}
+	return len(entries) > 0, nil
+}
+
+// backupProjectDir moves an existing project directory to a timestamped
+// sibling so the project can be generated from scratch
+func backupProjectDir(projectPath string) error {
+	backupPath := fmt.Sprintf("%s.backup-%s", projectPath, time.Now().Format("20060102-150405"))
+	if err := os.Rename(projectPath, backupPath); err != nil {
+		return fmt.Errorf("failed to back up existing directory: %w", err)
+	}
+	color.Yellow("📦 Moved existing directory to %s", backupPath)
 	return nil
 }
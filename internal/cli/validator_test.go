@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCheckProjectExists(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing map[string]string
+		force    bool
+		merge    bool
+		backup   bool
+		wantErr  string
+		backedUp bool
+	}{
+		{name: "new project"},
+		{name: "empty directory", existing: map[string]string{}},
+		{name: "refused", existing: map[string]string{"main.go": "mine"}, wantErr: "project directory already exists"},
+		{name: "force", existing: map[string]string{"main.go": "mine"}, force: true},
+		{name: "merge", existing: map[string]string{"main.go": "mine"}, merge: true},
+		{name: "backup", existing: map[string]string{"main.go": "mine"}, backup: true, backedUp: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			projectPath := filepath.Join(dir, "orders")
+			if tt.existing != nil {
+				if err := os.Mkdir(projectPath, 0755); err != nil {
+					t.Fatal(err)
+				}
+				for name, content := range tt.existing {
+					if err := os.WriteFile(filepath.Join(projectPath, name), []byte(content), 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			previousDir := targetDir
+			targetDir, force, merge, backup = dir, tt.force, tt.merge, tt.backup
+			t.Cleanup(func() { targetDir, force, merge, backup = previousDir, false, false, false })
+
+			err := checkProjectExists("orders")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("checkProjectExists() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("checkProjectExists() error = %v", err)
+			}
+
+			// Existing files are only ever moved, never changed
+			existingPath := projectPath
+			if tt.backedUp {
+				if _, err := os.Stat(projectPath); !os.IsNotExist(err) {
+					t.Errorf("project directory was not moved aside: %v", err)
+				}
+				matches, _ := filepath.Glob(projectPath + ".backup-*")
+				if len(matches) != 1 || !regexp.MustCompile(`/orders\.backup-\d{8}-\d{6}$`).MatchString(filepath.ToSlash(matches[0])) {
+					t.Fatalf("backups = %v, want one timestamped orders.backup-YYYYMMDD-HHMMSS", matches)
+				}
+				existingPath = matches[0]
+			}
+			for name, content := range tt.existing {
+				if data, err := os.ReadFile(filepath.Join(existingPath, name)); err != nil || string(data) != content {
+					t.Errorf("%s = %q, %v, want %q", name, data, err, content)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckProjectExistsNotADirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "orders"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	previousDir := targetDir
+	targetDir = dir
+	t.Cleanup(func() { targetDir = previousDir })
+
+	// No flag makes a file usable as project directory
+	for _, flag := range []*bool{nil, &force, &merge, &backup} {
+		if flag != nil {
+			*flag = true
+		}
+		if err := checkProjectExists("orders"); err == nil || !strings.Contains(err.Error(), "not a directory") {
+			t.Errorf("checkProjectExists() error = %v, want not a directory", err)
+		}
+		if flag != nil {
+			*flag = false
+		}
+	}
+}
+
+func TestProjectFlagsMutuallyExclusive(t *testing.T) {
+	for _, args := range [][]string{
+		{"--force", "--merge"},
+		{"--force", "--backup"},
+		{"--merge", "--backup"},
+	} {
+		resetProjectFlags(t)
+		if err := projectCmd.ParseFlags(args); err != nil {
+			t.Fatal(err)
+		}
+		err := projectCmd.ValidateFlagGroups()
+		resetProjectFlags(t)
+
+		if err == nil || !strings.Contains(err.Error(), "none of the others can be") {
+			t.Errorf("%v: error = %v, want a flag conflict", args, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/gomake/internal/generator"
+)
+
+// verifyProject builds and vets a project generated on disk
+func verifyProject(projectPath string) error {
+	log.Info("Verifying generated project", "path", projectPath)
+
+	if err := generator.Verify(projectPath); err != nil {
+		fmt.Fprintln(verifyOutput(), color.RedString("\n❌ Generated project does not build"))
+		return fmt.Errorf("verification failed: %w", err)
+	}
+
+	log.Success("Generated project builds and passes go vet")
+	return nil
+}
+
+// verifyOutput is where verification reports go: stderr when the archive
+// is streamed to stdout, so a failure can't corrupt it
+func verifyOutput() io.Writer {
+	if outputPath == "-" {
+		return color.Error
+	}
+	return color.Output
+}
+
+// verifyInTempDir generates the project into a temporary directory and
+// verifies it there, for output modes that never write a directory
+func verifyInTempDir(config *generator.Config) error {
+	tempDir, err := os.MkdirTemp("", "gomake-verify-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	tempConfig := *config
+	tempConfig.TargetDir = tempDir
+	tempConfig.WithGit = false
+
+	gen, err := generator.New(&tempConfig, log)
+	if err != nil {
+		return fmt.Errorf("failed to create generator: %w", err)
+	}
+
+	if err := gen.Generate(); err != nil {
+		return fmt.Errorf("failed to generate project: %w", err)
+	}
+
+	return verifyProject(filepath.Join(tempDir, config.ProjectName))
+}
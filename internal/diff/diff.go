@@ -0,0 +1,177 @@
+// Package diff compares and merges text line by line: shortest edit
+// scripts, unified diffs and three-way merges with conflict markers.
+package diff
+
+import (
+	"strings"
+)
+
+// Op is the kind of an edit
+type Op int
+
+const (
+	// Equal keeps a line of both texts
+	Equal Op = iota
+	// Delete removes a line of the first text
+	Delete
+	// Insert adds a line of the second text
+	Insert
+)
+
+// Edit is one line of an edit script. A and B are the indexes of the line
+// in the first and second text, -1 when it is not part of that text.
+type Edit struct {
+	Op   Op
+	Line string
+	A, B int
+}
+
+// Lines splits text into lines, keeping their line endings. The last line
+// has no line ending when text doesn't end with a newline.
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Edits returns a shortest edit script turning a into b, computed with
+// Myers' algorithm
+func Edits(a, b []string) []Edit {
+	// Common prefix and suffix don't need the search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]Edit, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, Edit{Op: Equal, Line: a[i], A: i, B: i})
+	}
+	for _, edit := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		if edit.A >= 0 {
+			edit.A += prefix
+		}
+		if edit.B >= 0 {
+			edit.B += prefix
+		}
+		edits = append(edits, edit)
+	}
+	for i := 0; i < suffix; i++ {
+		ia, ib := len(a)-suffix+i, len(b)-suffix+i
+		edits = append(edits, Edit{Op: Equal, Line: a[ia], A: ia, B: ib})
+	}
+	return edits
+}
+
+// myers finds a shortest edit script by exploring diagonals k = x - y for
+// increasing numbers of edits d, keeping the furthest x reached on each
+// diagonal, then backtracking through the recorded rounds
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	offset := max
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, offset)
+			}
+		}
+	}
+	return nil
+}
+
+func backtrack(a, b []string, trace [][]int, offset int) []Edit {
+	var reversed []Edit
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, Edit{Op: Equal, Line: a[x], A: x, B: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, Edit{Op: Insert, Line: b[prevY], A: -1, B: prevY})
+			} else {
+				reversed = append(reversed, Edit{Op: Delete, Line: a[prevX], A: prevX, B: -1})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	edits := make([]Edit, len(reversed))
+	for i, edit := range reversed {
+		edits[len(reversed)-1-i] = edit
+	}
+	return edits
+}
+
+// Stat returns the number of lines inserted and deleted turning a into b
+func Stat(a, b string) (insertions, deletions int) {
+	for _, edit := range Edits(Lines(a), Lines(b)) {
+		switch edit.Op {
+		case Insert:
+			insertions++
+		case Delete:
+			deletions++
+		}
+	}
+	return insertions, deletions
+}
+
+// matches maps each line of a kept in b to its index in b, -1 otherwise
+func matches(a, b []string) []int {
+	match := make([]int, len(a))
+	for i := range match {
+		match[i] = -1
+	}
+	for _, edit := range Edits(a, b) {
+		if edit.Op == Equal {
+			match[edit.A] = edit.B
+		}
+	}
+	return match
+}
@@ -0,0 +1,182 @@
+package diff
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// apply rebuilds both texts from an edit script
+func apply(edits []Edit) (a, b string) {
+	var ab, bb strings.Builder
+	for _, edit := range edits {
+		if edit.Op != Insert {
+			ab.WriteString(edit.Line)
+		}
+		if edit.Op != Delete {
+			bb.WriteString(edit.Line)
+		}
+	}
+	return ab.String(), bb.String()
+}
+
+func TestEdits(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		edits int
+	}{
+		{name: "equal", a: "a\nb\n", b: "a\nb\n", edits: 0},
+		{name: "empty to text", a: "", b: "a\nb\n", edits: 2},
+		{name: "text to empty", a: "a\nb\n", b: "", edits: 2},
+		{name: "replace middle", a: "a\nb\nc\n", b: "a\nx\nc\n", edits: 2},
+		{name: "insert and delete", a: "a\nb\nc\nd\n", b: "x\na\nc\nd\ny\n", edits: 3},
+		{name: "missing final newline", a: "a\nb", b: "a\nb\n", edits: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := Edits(Lines(tt.a), Lines(tt.b))
+
+			changes := 0
+			for _, edit := range edits {
+				if edit.Op != Equal {
+					changes++
+				}
+			}
+			if changes != tt.edits {
+				t.Errorf("got %d changes, want %d", changes, tt.edits)
+			}
+			if a, b := apply(edits); a != tt.a || b != tt.b {
+				t.Errorf("edits rebuild %q and %q", a, b)
+			}
+		})
+	}
+}
+
+func TestEditsRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomText := func() string {
+		var b strings.Builder
+		for i := rng.Intn(30); i > 0; i-- {
+			b.WriteString(string(rune('a'+rng.Intn(4))) + "\n")
+		}
+		return b.String()
+	}
+
+	for i := 0; i < 200; i++ {
+		a, b := randomText(), randomText()
+		if gotA, gotB := apply(Edits(Lines(a), Lines(b))); gotA != a || gotB != b {
+			t.Fatalf("edits of %q -> %q rebuild %q -> %q", a, b, gotA, gotB)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	labels := Labels{Ours: "current", Theirs: "new"}
+
+	tests := []struct {
+		name      string
+		base      string
+		ours      string
+		theirs    string
+		want      string
+		conflicts int
+	}{
+		{
+			name:   "only theirs changed",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nB\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "only ours changed",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\nd\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\nd\n",
+		},
+		{
+			name:   "separate regions",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:   "same change on both sides",
+			base:   "a\nb\n",
+			ours:   "a\nx\n",
+			theirs: "a\nx\n",
+			want:   "a\nx\n",
+		},
+		{
+			name:      "conflicting change",
+			base:      "a\nb\nc\n",
+			ours:      "a\nours\nc\n",
+			theirs:    "a\ntheirs\nc\n",
+			want:      "a\n<<<<<<< current\nours\n=======\ntheirs\n>>>>>>> new\nc\n",
+			conflicts: 1,
+		},
+		{
+			name:      "conflict without final newline",
+			base:      "a\nb",
+			ours:      "a\nx",
+			theirs:    "a\ny",
+			want:      "a\n<<<<<<< current\nx\n=======\ny\n>>>>>>> new\n",
+			conflicts: 1,
+		},
+		{
+			name:   "deletion and insertion elsewhere",
+			base:   "a\nb\nc\nd\n",
+			ours:   "a\nc\nd\n",
+			theirs: "a\nb\nc\nd\ne\n",
+			want:   "a\nc\nd\ne\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Merge(tt.base, tt.ours, tt.theirs, labels)
+			if result.Text != tt.want {
+				t.Errorf("Merge() =\n%s\nwant\n%s", result.Text, tt.want)
+			}
+			if result.Conflicts != tt.conflicts {
+				t.Errorf("Merge() conflicts = %d, want %d", result.Conflicts, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	b := "one\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+
+	want := `--- a/numbers
++++ b/numbers
+@@ -1,5 +1,5 @@
+ one
+-two
++2
+ three
+ four
+ five
+@@ -8,3 +8,4 @@
+ eight
+ nine
+ ten
++eleven
+`
+	if got := Unified("a/numbers", "b/numbers", a, b, 3); got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Unified("a", "b", a, a, 3); got != "" {
+		t.Errorf("Unified() of equal texts = %q, want empty", got)
+	}
+
+	if got := Unified("a", "b", "", "x", 3); got != "--- a\n+++ b\n@@ -0,0 +1 @@\n+x\n\\ No newline at end of file\n" {
+		t.Errorf("Unified() from empty = %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+package diff
+
+import (
+	"strings"
+)
+
+// Labels name the sides of a merge in conflict markers
+type Labels struct {
+	Ours   string
+	Theirs string
+}
+
+// MergeResult is the outcome of a three-way merge
+type MergeResult struct {
+	Text string
+	// Conflicts is the number of regions changed differently on both
+	// sides; each one is wrapped in conflict markers in Text
+	Conflicts int
+}
+
+// Merge merges the changes from base to ours and from base to theirs.
+// Regions changed on one side only take that side, regions changed the
+// same way on both sides are kept once, and regions changed differently
+// become conflicts:
+//
+//	<<<<<<< ours
+//	...
+//	=======
+//	...
+//	>>>>>>> theirs
+func Merge(base, ours, theirs string, labels Labels) MergeResult {
+	o, a, b := Lines(base), Lines(ours), Lines(theirs)
+	matchA, matchB := matches(o, a), matches(o, b)
+
+	var out strings.Builder
+	var result MergeResult
+	i, j, k := 0, 0, 0
+
+	for {
+		// Copy the lines kept unchanged by both sides
+		stable := 0
+		for i+stable < len(o) && matchA[i+stable] == j+stable && matchB[i+stable] == k+stable {
+			stable++
+		}
+		if stable > 0 {
+			writeLines(&out, o[i:i+stable])
+			i, j, k = i+stable, j+stable, k+stable
+			continue
+		}
+
+		// Find the next base line kept by both sides; everything before
+		// it was changed by at least one of them
+		next := i
+		for next < len(o) && (matchA[next] < 0 || matchB[next] < 0) {
+			next++
+		}
+
+		var chunkO, chunkA, chunkB []string
+		if next == len(o) {
+			chunkO, chunkA, chunkB = o[i:], a[j:], b[k:]
+		} else {
+			chunkO, chunkA, chunkB = o[i:next], a[j:matchA[next]], b[k:matchB[next]]
+		}
+
+		if len(chunkO)+len(chunkA)+len(chunkB) > 0 {
+			switch {
+			case equalLines(chunkA, chunkO):
+				writeLines(&out, chunkB)
+			case equalLines(chunkB, chunkO), equalLines(chunkA, chunkB):
+				writeLines(&out, chunkA)
+			default:
+				result.Conflicts++
+				writeConflict(&out, chunkA, chunkB, labels)
+			}
+		}
+
+		if next == len(o) {
+			break
+		}
+		i, j, k = next, matchA[next], matchB[next]
+	}
+
+	result.Text = out.String()
+	return result
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+}
+
+// writeConflict writes both sides between conflict markers, making sure
+// each marker starts on its own line
+func writeConflict(out *strings.Builder, ours, theirs []string, labels Labels) {
+	ensureNewline(out)
+	out.WriteString("<<<<<<< " + labels.Ours + "\n")
+	writeLines(out, ours)
+	ensureNewline(out)
+	out.WriteString("=======\n")
+	writeLines(out, theirs)
+	ensureNewline(out)
+	out.WriteString(">>>>>>> " + labels.Theirs + "\n")
+}
+
+func ensureNewline(out *strings.Builder) {
+	if out.Len() > 0 && !strings.HasSuffix(out.String(), "\n") {
+		out.WriteString("\n")
+	}
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
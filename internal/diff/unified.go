@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unified returns a unified diff turning a into b with the given number of
+// context lines around each change, or "" when they are equal
+func Unified(fromName, toName, a, b string, context int) string {
+	edits := Edits(Lines(a), Lines(b))
+
+	var out strings.Builder
+	for _, hunk := range hunks(edits, context) {
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		writeHunk(&out, edits, hunk[0], hunk[1])
+	}
+	return out.String()
+}
+
+// hunks returns the [start, end) ranges of edits holding changes with
+// their surrounding context, merging ranges whose context overlaps
+func hunks(edits []Edit, context int) [][2]int {
+	var ranges [][2]int
+
+	for i, edit := range edits {
+		if edit.Op == Equal {
+			continue
+		}
+
+		from, to := i-context, i+context+1
+		if from < 0 {
+			from = 0
+		}
+		if to > len(edits) {
+			to = len(edits)
+		}
+
+		if n := len(ranges); n > 0 && from <= ranges[n-1][1] {
+			ranges[n-1][1] = to
+			continue
+		}
+		ranges = append(ranges, [2]int{from, to})
+	}
+	return ranges
+}
+
+func writeHunk(out *strings.Builder, edits []Edit, start, end int) {
+	// Count the lines of each side before and inside the hunk
+	fromBefore, toBefore := 0, 0
+	for _, edit := range edits[:start] {
+		if edit.Op != Insert {
+			fromBefore++
+		}
+		if edit.Op != Delete {
+			toBefore++
+		}
+	}
+	fromCount, toCount := 0, 0
+	for _, edit := range edits[start:end] {
+		if edit.Op != Insert {
+			fromCount++
+		}
+		if edit.Op != Delete {
+			toCount++
+		}
+	}
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(fromBefore, fromCount), hunkRange(toBefore, toCount))
+	for _, edit := range edits[start:end] {
+		prefix := " "
+		switch edit.Op {
+		case Delete:
+			prefix = "-"
+		case Insert:
+			prefix = "+"
+		}
+		out.WriteString(prefix + edit.Line)
+		if !strings.HasSuffix(edit.Line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the range of a hunk given the number of lines before
+// it. Line numbers are 1-based; an empty range names the line before it.
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, count)
+	}
+}
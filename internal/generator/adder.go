@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"fmt"
+)
+
+// Adder adds parts of a generated project, such as Docker files or a
+// feature module, to an existing project
+type Adder struct {
+	config      *Config
+	projectPath string
+	logger      Logger
+	pipeline    *Pipeline
+	recorder    *checksumRecorder
+
+	// options record the added options in the manifest
+	options []func(*ManifestOptions)
+}
+
+// NewAdder creates an adder for the project described by config in
+// projectPath. Pass a NoClobberWriter to keep existing files untouched.
+func NewAdder(config *Config, projectPath string, logger Logger, writer Writer) (*Adder, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	templates, err := NewTemplateManager(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := newChecksumRecorder()
+	return &Adder{
+		config:      config,
+		projectPath: projectPath,
+		logger:      logger,
+		pipeline:    NewPipeline(templates, writer, append(DefaultPostProcessors(config), recorder.Record)...),
+		recorder:    recorder,
+	}, nil
+}
+
+// Config returns the configuration of the project
+func (a *Adder) Config() *Config {
+	return a.config
+}
+
+// AddDocker adds a Dockerfile, docker-compose.yml and .dockerignore
+func (a *Adder) AddDocker() error {
+	a.config.WithDocker = true
+	if err := NewDockerGenerator(a.config, a.logger, a.pipeline).Generate(a.projectPath); err != nil {
+		return err
+	}
+
+	a.options = append(a.options, func(options *ManifestOptions) {
+		options.Docker = true
+	})
+	return nil
+}
+
+// AddLicense adds a LICENSE file. The project keeps the license it was
+// generated with for the headers of its files, which are not rewritten.
+func (a *Adder) AddLicense(license string) error {
+	licensed := *a.config
+	licensed.License = license
+	return NewLicenseGenerator(&licensed, a.logger, a.pipeline).Generate(a.projectPath)
+}
+
+// AddFeature adds the directories and files of a feature module and the
+// modules it requires. It returns the added modules; their go.mod
+// requirements, .env keys, services and Makefile targets touch existing
+// files and are left to the caller to report.
+func (a *Adder) AddFeature(name string) ([]*FeatureModule, error) {
+	modules, err := ResolveFeatures(append(append([]string(nil), a.config.Features...), name))
+	if err != nil {
+		return nil, err
+	}
+
+	var added []*FeatureModule
+	for _, module := range modules {
+		if !hasFeature(module.Name, a.config.Features) {
+			added = append(added, module)
+		}
+	}
+	for _, module := range added {
+		a.config.Features = append(a.config.Features, module.Name)
+	}
+
+	data := NewTemplateData(a.config)
+	for _, module := range added {
+		a.logger.Info("Adding feature", "feature", module.Name)
+		if err := renderFeature(a.pipeline, a.projectPath, module, data); err != nil {
+			return nil, err
+		}
+	}
+
+	a.options = append(a.options, func(options *ManifestOptions) {
+		for _, module := range added {
+			if !hasFeature(module.Name, options.Features) {
+				options.Features = append(options.Features, module.Name)
+			}
+		}
+	})
+	return added, nil
+}
+
+// UpdateManifest records the added files and options in the project
+// manifest. Nothing is recorded when existing files were left untouched by
+// a NoClobberWriter, as the additions are then incomplete. Projects
+// without a manifest are left alone.
+func (a *Adder) UpdateManifest() error {
+	if writer, ok := a.pipeline.Writer().(*NoClobberWriter); ok && len(writer.Conflicts()) > 0 {
+		return nil
+	}
+
+	return updateProjectManifest(a.projectPath, a.config, a.pipeline.Templates(), a.recorder, func(options *ManifestOptions) {
+		for _, set := range a.options {
+			set(options)
+		}
+	})
+}
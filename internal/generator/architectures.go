@@ -1,25 +1,41 @@
 package generator
 
-import (
-	"fmt"
-	"os"
-	"path/filepath"
-)
+func init() {
+	RegisterArchitecture(ArchitectureSpec{
+		Name:        "hexagonal",
+		Description: "Ports & adapters with a core isolated from infrastructure",
+		New:         func() Architecture { return NewHexagonalArchitecture() },
+		Data:        func(config *Config) interface{} { return NewHexagonalData(config) },
+		Components:  hexagonalComponents,
+	})
+	RegisterArchitecture(ArchitectureSpec{
+		Name:        "clean",
+		Description: "Clean architecture with domain, use case and delivery layers",
+		New:         func() Architecture { return NewCleanArchitecture() },
+		Data:        func(config *Config) interface{} { return NewCleanData(config) },
+		Components:  cleanComponents,
+	})
+	RegisterArchitecture(ArchitectureSpec{
+		Name:        "mvc",
+		Description: "Model-View-Controller with routes and middleware",
+		New:         func() Architecture { return NewMVCArchitecture() },
+		Data:        func(config *Config) interface{} { return NewMVCData(config) },
+		Components:  mvcComponents,
+	})
+	RegisterArchitecture(ArchitectureSpec{
+		Name:        "basic",
+		Description: "Simple internal/ and pkg/ layout for small services",
+		New:         func() Architecture { return NewBasicArchitecture() },
+		Data:        func(config *Config) interface{} { return NewBasicData(config) },
+		Components:  basicComponents,
+	})
+}
 
 // HexagonalArchitecture implements hexagonal (ports & adapters) architecture
-type HexagonalArchitecture struct {
-	templateManager *TemplateManager
-}
+type HexagonalArchitecture struct{}
 
 func NewHexagonalArchitecture() *HexagonalArchitecture {
-	tm, err := NewTemplateManager()
-	if err != nil {
-		panic(fmt.Sprintf("Failed to create template manager: %v", err))
-	}
-	
-	return &HexagonalArchitecture{
-		templateManager: tm,
-	}
+	return &HexagonalArchitecture{}
 }
 
 func (h *HexagonalArchitecture) GetName() string {
@@ -28,7 +44,7 @@ func (h *HexagonalArchitecture) GetName() string {
 
 func (h *HexagonalArchitecture) GetStructure() *ProjectStructure {
 	structure := NewProjectStructure()
-	
+
 	// Add directories based on your specification
 	dirs := []string{
 		"cmd",
@@ -48,68 +64,37 @@ func (h *HexagonalArchitecture) GetStructure() *ProjectStructure {
 		"pkg/database",
 		"pkg/initializers",
 	}
-	
+
 	for _, dir := range dirs {
 		structure.AddDirectory(dir)
 	}
-	
-	return structure
-}
 
-func (h *HexagonalArchitecture) GenerateFiles(projectPath string, config *Config) error {
-	templateData := NewTemplateData(config)
-	
-	files := map[string]string{
-		fmt.Sprintf("cmd/%s/main.go", config.ProjectName):     "hexagonal/main.go",
-		".env":                                                "common/env",
-		"internal/adapters/cache/cache.go":                    "hexagonal/cache.go",
-		"pkg/logger/logger.go":                                "common/logger",
-		"pkg/utils/utils.go":                                  "common/utils",
-		"pkg/database/database.go":                            "common/database",
-		"configs/config.go":                                   "common/config",
-	}
-	
-	return h.renderAndWriteFiles(projectPath, files, templateData)
+	return structure
 }
 
-func (h *HexagonalArchitecture) renderAndWriteFiles(projectPath string, files map[string]string, data *TemplateData) error {
-	for filePath, templateName := range files {
-		content, err := h.templateManager.RenderTemplate(templateName, data)
-		if err != nil {
-			return fmt.Errorf("failed to render template %s: %w", templateName, err)
-		}
-		
-		fullPath := filepath.Join(projectPath, filePath)
-		
-		// Create directory if it doesn't exist
-		dir := filepath.Dir(fullPath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
-		}
-		
-		// Write file
-		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
-			return fmt.Errorf("failed to write file %s: %w", filePath, err)
-		}
-	}
-	
-	return nil
+func (h *HexagonalArchitecture) Files(config *Config) ([]PlannedFile, error) {
+	return plannedTemplates(map[string]string{
+		"cmd/{{.ProjectName}}/main.go":                    "hexagonal/main.go",
+		".env":                                            "common/env",
+		"internal/adapters/cache/cache.go":                "hexagonal/cache.go",
+		"internal/adapters/handler/user_handler.go":       "hexagonal/handler.go",
+		"internal/adapters/repository/user_repository.go": "hexagonal/repository.go",
+		"internal/config/config.go":                       "common/config",
+		"internal/core/domain/user.go":                    "hexagonal/domain.go",
+		"internal/core/ports/user.go":                     "hexagonal/ports.go",
+		"internal/core/services/user_service.go":          "hexagonal/service.go",
+		"pkg/initializers/env.go":                         "common/initializers",
+		"pkg/logger/logger.go":                            "common/logger",
+		"pkg/utils/utils.go":                              "common/utils",
+		"pkg/database/database.go":                        "common/database",
+	}), nil
 }
 
 // CleanArchitecture implements clean architecture
-type CleanArchitecture struct {
-	templateManager *TemplateManager
-}
+type CleanArchitecture struct{}
 
 func NewCleanArchitecture() *CleanArchitecture {
-	tm, err := NewTemplateManager()
-	if err != nil {
-		panic(fmt.Sprintf("Failed to create template manager: %v", err))
-	}
-	
-	return &CleanArchitecture{
-		templateManager: tm,
-	}
+	return &CleanArchitecture{}
 }
 
 func (c *CleanArchitecture) GetName() string {
@@ -118,13 +103,13 @@ func (c *CleanArchitecture) GetName() string {
 
 func (c *CleanArchitecture) GetStructure() *ProjectStructure {
 	structure := NewProjectStructure()
-	
+
 	dirs := []string{
 		"cmd",
 		"app",
 		"domain",
 		"repository",
-		"usecase", 
+		"usecase",
 		"delivery/http",
 		"delivery/http/middleware",
 		"infrastructure/database",
@@ -135,67 +120,37 @@ func (c *CleanArchitecture) GetStructure() *ProjectStructure {
 		"docs",
 		"scripts",
 	}
-	
+
 	for _, dir := range dirs {
 		structure.AddDirectory(dir)
 	}
-	
-	return structure
-}
 
-func (c *CleanArchitecture) GenerateFiles(projectPath string, config *Config) error {
-	templateData := NewTemplateData(config)
-	
-	files := map[string]string{
-		fmt.Sprintf("cmd/%s/main.go", config.ProjectName): "clean/main.go",
-		".env":                                            "common/env",
-		"pkg/logger/logger.go":                            "common/logger",
-		"pkg/utils/utils.go":                              "common/utils",
-		"pkg/database/database.go":                        "common/database",
-		"configs/config.go":                               "common/config",
-	}
-	
-	return c.renderAndWriteFiles(projectPath, files, templateData)
+	return structure
 }
 
-func (c *CleanArchitecture) renderAndWriteFiles(projectPath string, files map[string]string, data *TemplateData) error {
-	for filePath, templateName := range files {
-		content, err := c.templateManager.RenderTemplate(templateName, data)
-		if err != nil {
-			return fmt.Errorf("failed to render template %s: %w", templateName, err)
-		}
-		
-		fullPath := filepath.Join(projectPath, filePath)
-		
-		// Create directory if it doesn't exist
-		dir := filepath.Dir(fullPath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
-		}
-		
-		// Write file
-		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
-			return fmt.Errorf("failed to write file %s: %w", filePath, err)
-		}
-	}
-	
-	return nil
+func (c *CleanArchitecture) Files(config *Config) ([]PlannedFile, error) {
+	return plannedTemplates(map[string]string{
+		"cmd/{{.ProjectName}}/main.go":        "clean/main.go",
+		".env":                                "common/env",
+		"app/app.go":                          "clean/app.go",
+		"configs/config.go":                   "common/config",
+		"delivery/http/user_handler.go":       "clean/handler.go",
+		"delivery/http/middleware/logging.go": "clean/middleware.go",
+		"domain/user.go":                      "clean/domain.go",
+		"repository/user_repository.go":       "clean/repository.go",
+		"usecase/user_usecase.go":             "clean/usecase.go",
+		"pkg/initializers/env.go":             "common/initializers",
+		"pkg/logger/logger.go":                "common/logger",
+		"pkg/utils/utils.go":                  "common/utils",
+		"pkg/database/database.go":            "common/database",
+	}), nil
 }
 
 // MVCArchitecture implements MVC pattern
-type MVCArchitecture struct {
-	templateManager *TemplateManager
-}
+type MVCArchitecture struct{}
 
 func NewMVCArchitecture() *MVCArchitecture {
-	tm, err := NewTemplateManager()
-	if err != nil {
-		panic(fmt.Sprintf("Failed to create template manager: %v", err))
-	}
-	
-	return &MVCArchitecture{
-		templateManager: tm,
-	}
+	return &MVCArchitecture{}
 }
 
 func (m *MVCArchitecture) GetName() string {
@@ -204,7 +159,7 @@ func (m *MVCArchitecture) GetName() string {
 
 func (m *MVCArchitecture) GetStructure() *ProjectStructure {
 	structure := NewProjectStructure()
-	
+
 	dirs := []string{
 		"cmd",
 		"app",
@@ -216,6 +171,7 @@ func (m *MVCArchitecture) GetStructure() *ProjectStructure {
 		"database",
 		"migrations",
 		"seeders",
+		"pkg/initializers",
 		"pkg/logger",
 		"pkg/utils",
 		"pkg/validators",
@@ -224,67 +180,37 @@ func (m *MVCArchitecture) GetStructure() *ProjectStructure {
 		"storage/logs",
 		"tests",
 	}
-	
+
 	for _, dir := range dirs {
 		structure.AddDirectory(dir)
 	}
-	
-	return structure
-}
 
-func (m *MVCArchitecture) GenerateFiles(projectPath string, config *Config) error {
-	templateData := NewTemplateData(config)
-	
-	files := map[string]string{
-		fmt.Sprintf("cmd/%s/main.go", config.ProjectName): "mvc/main.go",
-		".env":                                            "common/env",
-		"pkg/logger/logger.go":                            "common/logger",
-		"pkg/utils/utils.go":                              "common/utils",
-		"pkg/database/database.go":                        "common/database",
-		"configs/config.go":                               "common/config",
-	}
-	
-	return m.renderAndWriteFiles(projectPath, files, templateData)
+	return structure
 }
 
-func (m *MVCArchitecture) renderAndWriteFiles(projectPath string, files map[string]string, data *TemplateData) error {
-	for filePath, templateName := range files {
-		content, err := m.templateManager.RenderTemplate(templateName, data)
-		if err != nil {
-			return fmt.Errorf("failed to render template %s: %w", templateName, err)
-		}
-		
-		fullPath := filepath.Join(projectPath, filePath)
-		
-		// Create directory if it doesn't exist
-		dir := filepath.Dir(fullPath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
-		}
-		
-		// Write file
-		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
-			return fmt.Errorf("failed to write file %s: %w", filePath, err)
-		}
-	}
-	
-	return nil
+func (m *MVCArchitecture) Files(config *Config) ([]PlannedFile, error) {
+	return plannedTemplates(map[string]string{
+		"cmd/{{.ProjectName}}/main.go":   "mvc/main.go",
+		".env":                           "common/env",
+		"app/app.go":                     "mvc/app.go",
+		"configs/config.go":              "common/config",
+		"controllers/user_controller.go": "mvc/controller.go",
+		"middleware/logging.go":          "mvc/middleware.go",
+		"models/user.go":                 "mvc/model.go",
+		"routes/routes.go":               "mvc/routes.go",
+		"views/json.go":                  "mvc/view.go",
+		"pkg/initializers/env.go":        "common/initializers",
+		"pkg/logger/logger.go":           "common/logger",
+		"pkg/utils/utils.go":             "common/utils",
+		"pkg/database/database.go":       "common/database",
+	}), nil
 }
 
 // BasicArchitecture implements basic Go project structure
-type BasicArchitecture struct {
-	templateManager *TemplateManager
-}
+type BasicArchitecture struct{}
 
 func NewBasicArchitecture() *BasicArchitecture {
-	tm, err := NewTemplateManager()
-	if err != nil {
-		panic(fmt.Sprintf("Failed to create template manager: %v", err))
-	}
-	
-	return &BasicArchitecture{
-		templateManager: tm,
-	}
+	return &BasicArchitecture{}
 }
 
 func (b *BasicArchitecture) GetName() string {
@@ -293,11 +219,12 @@ func (b *BasicArchitecture) GetName() string {
 
 func (b *BasicArchitecture) GetStructure() *ProjectStructure {
 	structure := NewProjectStructure()
-	
+
 	dirs := []string{
 		"cmd",
 		"internal/app",
 		"internal/handlers",
+		"internal/models",
 		"internal/services",
 		"internal/repository",
 		"pkg/logger",
@@ -308,49 +235,127 @@ func (b *BasicArchitecture) GetStructure() *ProjectStructure {
 		"scripts",
 		"tests",
 	}
-	
+
 	for _, dir := range dirs {
 		structure.AddDirectory(dir)
 	}
-	
+
 	return structure
 }
 
-func (b *BasicArchitecture) GenerateFiles(projectPath string, config *Config) error {
-	templateData := NewTemplateData(config)
-	
-	files := map[string]string{
-		fmt.Sprintf("cmd/%s/main.go", config.ProjectName): "basic/main.go",
-		".env":                                            "common/env",
-		"pkg/logger/logger.go":                            "common/logger",
-		"pkg/utils/utils.go":                              "common/utils",
-		"pkg/database/database.go":                        "common/database",
-		"configs/config.go":                               "common/config",
-	}
-	
-	return b.renderAndWriteFiles(projectPath, files, templateData)
+func (b *BasicArchitecture) Files(config *Config) ([]PlannedFile, error) {
+	return plannedTemplates(map[string]string{
+		"cmd/{{.ProjectName}}/main.go":           "basic/main.go",
+		".env":                                   "common/env",
+		"configs/config.go":                      "common/config",
+		"internal/app/app.go":                    "basic/app.go",
+		"internal/handlers/user_handler.go":      "basic/handler.go",
+		"internal/models/user.go":                "basic/model.go",
+		"internal/repository/user_repository.go": "basic/repository.go",
+		"internal/services/user_service.go":      "basic/service.go",
+		"pkg/initializers/env.go":                "common/initializers",
+		"pkg/logger/logger.go":                   "common/logger",
+		"pkg/utils/utils.go":                     "common/utils",
+		"pkg/database/database.go":               "common/database",
+	}), nil
 }
 
-func (b *BasicArchitecture) renderAndWriteFiles(projectPath string, files map[string]string, data *TemplateData) error {
-	for filePath, templateName := range files {
-		content, err := b.templateManager.RenderTemplate(templateName, data)
-		if err != nil {
-			return fmt.Errorf("failed to render template %s: %w", templateName, err)
-		}
-		
-		fullPath := filepath.Join(projectPath, filePath)
-		
-		// Create directory if it doesn't exist
-		dir := filepath.Dir(fullPath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
-		}
-		
-		// Write file
-		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
-			return fmt.Errorf("failed to write file %s: %w", filePath, err)
-		}
+// plannedTemplates plans a file per path rendered from the named template
+func plannedTemplates(files map[string]string) []PlannedFile {
+	planned := make([]PlannedFile, 0, len(files))
+	for path, templateName := range files {
+		planned = append(planned, PlannedFile{Path: path, Template: templateName})
 	}
-	
-	return nil
+	return planned
+}
+
+// Component layouts used by gomake generate
+
+var hexagonalComponents = &ComponentLayout{
+	EntityPackage: "domain",
+	Files: map[ComponentKind][]PlannedFile{
+		ComponentEntity: plannedTemplates(map[string]string{
+			"internal/core/domain/{{.Component.File}}.go":      "components/hexagonal/domain.go",
+			"internal/core/domain/{{.Component.File}}_test.go": "components/common/entity_test.go",
+			"internal/core/ports/{{.Component.File}}.go":       "components/hexagonal/ports.go",
+		}),
+		ComponentRepository: plannedTemplates(map[string]string{
+			"internal/adapters/repository/{{.Component.File}}_repository.go": "components/hexagonal/repository.go",
+		}),
+		ComponentService: plannedTemplates(map[string]string{
+			"internal/core/services/{{.Component.File}}_service.go":      "components/hexagonal/service.go",
+			"internal/core/services/{{.Component.File}}_service_test.go": "components/hexagonal/service_test.go",
+		}),
+		ComponentHandler: plannedTemplates(map[string]string{
+			"internal/adapters/handler/{{.Component.File}}_handler.go": "components/hexagonal/handler.go",
+		}),
+	},
+	Wiring: `{{.Component.Var}}Repo := repository.NewMemory{{.Component.Name}}Repository()
+{{.Component.Var}}Service := services.New{{.Component.Name}}Service({{.Component.Var}}Repo)
+handler.New{{.Component.Name}}Handler({{.Component.Var}}Service).Register(mux)`,
+}
+
+var cleanComponents = &ComponentLayout{
+	EntityPackage: "domain",
+	Files: map[ComponentKind][]PlannedFile{
+		ComponentEntity: plannedTemplates(map[string]string{
+			"domain/{{.Component.File}}.go":      "components/clean/domain.go",
+			"domain/{{.Component.File}}_test.go": "components/common/entity_test.go",
+		}),
+		ComponentRepository: plannedTemplates(map[string]string{
+			"repository/{{.Component.File}}_repository.go": "components/clean/repository.go",
+		}),
+		ComponentService: plannedTemplates(map[string]string{
+			"usecase/{{.Component.File}}_usecase.go":      "components/clean/usecase.go",
+			"usecase/{{.Component.File}}_usecase_test.go": "components/clean/usecase_test.go",
+		}),
+		ComponentHandler: plannedTemplates(map[string]string{
+			"delivery/http/{{.Component.File}}_handler.go": "components/clean/handler.go",
+		}),
+	},
+	Wiring: `{{.Component.Var}}Repo := repository.NewMemory{{.Component.Name}}Repository()
+{{.Component.Var}}Usecase := usecase.New{{.Component.Name}}Usecase({{.Component.Var}}Repo)
+deliveryhttp.New{{.Component.Name}}Handler({{.Component.Var}}Usecase).Register(mux)`,
+}
+
+var mvcComponents = &ComponentLayout{
+	EntityPackage: "models",
+	Files: map[ComponentKind][]PlannedFile{
+		ComponentEntity: plannedTemplates(map[string]string{
+			"models/{{.Component.File}}.go":      "components/mvc/model.go",
+			"models/{{.Component.File}}_test.go": "components/common/entity_test.go",
+		}),
+		ComponentRepository: plannedTemplates(map[string]string{
+			"models/{{.Component.File}}_store.go":      "components/mvc/store.go",
+			"models/{{.Component.File}}_store_test.go": "components/mvc/store_test.go",
+		}),
+		ComponentHandler: plannedTemplates(map[string]string{
+			"controllers/{{.Component.File}}_controller.go": "components/mvc/controller.go",
+			"routes/{{.Component.File}}_routes.go":          "components/mvc/routes.go",
+		}),
+	},
+	Wiring: `routes.Register{{.Component.Name}}Routes(mux, models.NewMemory{{.Component.Name}}Store())`,
+}
+
+var basicComponents = &ComponentLayout{
+	EntityPackage: "models",
+	Files: map[ComponentKind][]PlannedFile{
+		ComponentEntity: plannedTemplates(map[string]string{
+			"internal/models/{{.Component.File}}.go":      "components/basic/model.go",
+			"internal/models/{{.Component.File}}_test.go": "components/common/entity_test.go",
+		}),
+		ComponentRepository: plannedTemplates(map[string]string{
+			"internal/repository/{{.Component.File}}_repository.go": "components/basic/repository.go",
+		}),
+		ComponentService: plannedTemplates(map[string]string{
+			"internal/services/{{.Component.File}}_service.go":      "components/basic/service.go",
+			"internal/services/{{.Component.File}}_service_test.go": "components/basic/service_test.go",
+		}),
+		ComponentHandler: plannedTemplates(map[string]string{
+			"internal/handlers/{{.Component.File}}_handler.go": "components/basic/handler.go",
+		}),
+	},
+	Wiring: `{{.Component.Var}}Repo := repository.NewMemory{{.Component.Name}}Repository()
+{{.Component.Var}}Service := services.New{{.Component.Name}}Service({{.Component.Var}}Repo)
+handlers.New{{.Component.Name}}Handler({{.Component.Var}}Service).Register(mux)`,
 }
@@ -0,0 +1,172 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchiveFormat is the container format produced by ArchiveWriter
+type ArchiveFormat string
+
+const (
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+	ArchiveZip   ArchiveFormat = "zip"
+)
+
+// ArchiveFormatFromPath detects the archive format from a file name
+func ArchiveFormatFromPath(path string) (ArchiveFormat, error) {
+	switch {
+	case strings.HasSuffix(path, ".tar.gz"), strings.HasSuffix(path, ".tgz"):
+		return ArchiveTarGz, nil
+	case strings.HasSuffix(path, ".zip"):
+		return ArchiveZip, nil
+	default:
+		return "", fmt.Errorf("unsupported archive format: %s (use .tar.gz, .tgz or .zip)", path)
+	}
+}
+
+// ArchiveWriter collects project output in memory so it can be written out
+// as a single archive. Entry names are relative to root.
+type ArchiveWriter struct {
+	*MemoryWriter
+	format  ArchiveFormat
+	root    string
+	modTime time.Time
+}
+
+// NewArchiveWriter creates a new archive writer
+func NewArchiveWriter(format ArchiveFormat, root string) *ArchiveWriter {
+	return &ArchiveWriter{
+		MemoryWriter: NewMemoryWriter(),
+		format:       format,
+		root:         root,
+		modTime:      now(),
+	}
+}
+
+// WriteArchive writes everything recorded so far as an archive to out
+func (aw *ArchiveWriter) WriteArchive(out io.Writer) error {
+	switch aw.format {
+	case ArchiveTarGz:
+		return aw.writeTarGz(out)
+	case ArchiveZip:
+		return aw.writeZip(out)
+	default:
+		return fmt.Errorf("unsupported archive format: %s", aw.format)
+	}
+}
+
+func (aw *ArchiveWriter) writeTarGz(out io.Writer) error {
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	for _, dir := range aw.entryDirs() {
+		header := &tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     dir + "/",
+			Mode:     0755,
+			ModTime:  aw.modTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write archive entry %s: %w", dir, err)
+		}
+	}
+
+	for _, file := range aw.Files() {
+		name := aw.entryName(file.Path)
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     int64(file.Mode.Perm()),
+			Size:     int64(len(file.Data)),
+			ModTime:  aw.modTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write archive entry %s: %w", name, err)
+		}
+		if _, err := tw.Write(file.Data); err != nil {
+			return fmt.Errorf("failed to write archive entry %s: %w", name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish tar archive: %w", err)
+	}
+	return gz.Close()
+}
+
+func (aw *ArchiveWriter) writeZip(out io.Writer) error {
+	zw := zip.NewWriter(out)
+
+	for _, dir := range aw.entryDirs() {
+		header := &zip.FileHeader{
+			Name:     dir + "/",
+			Modified: aw.modTime,
+		}
+		header.SetMode(os.ModeDir | 0755)
+		if _, err := zw.CreateHeader(header); err != nil {
+			return fmt.Errorf("failed to write archive entry %s: %w", dir, err)
+		}
+	}
+
+	for _, file := range aw.Files() {
+		name := aw.entryName(file.Path)
+		header := &zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: aw.modTime,
+		}
+		header.SetMode(file.Mode.Perm())
+
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to write archive entry %s: %w", name, err)
+		}
+		if _, err := w.Write(file.Data); err != nil {
+			return fmt.Errorf("failed to write archive entry %s: %w", name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// entryDirs returns every directory entry, including parents of files,
+// in sorted order so parents precede children
+func (aw *ArchiveWriter) entryDirs() []string {
+	seen := make(map[string]bool)
+	var dirs []string
+
+	addDir := func(dir string) {
+		for dir != "." && dir != "" && !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+			dir = filepath.ToSlash(filepath.Dir(dir))
+		}
+	}
+
+	for _, dir := range aw.Dirs() {
+		addDir(aw.entryName(dir))
+	}
+	for _, file := range aw.Files() {
+		addDir(filepath.ToSlash(filepath.Dir(aw.entryName(file.Path))))
+	}
+
+	sort.Strings(dirs)
+	return dirs
+}
+
+func (aw *ArchiveWriter) entryName(path string) string {
+	rel, err := filepath.Rel(aw.root, path)
+	if err != nil {
+		rel = path
+	}
+	return filepath.ToSlash(rel)
+}
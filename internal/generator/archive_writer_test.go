@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// archiveEntry is an archive entry as read back from the archive
+type archiveEntry struct {
+	Mode os.FileMode
+	Data string
+}
+
+// archiveFixture records a small project under root
+func archiveFixture(format ArchiveFormat) *ArchiveWriter {
+	root := filepath.Join("out", "orders")
+	aw := NewArchiveWriter(format, root)
+	aw.MkdirAll(filepath.Join(root, "internal", "domain"))
+	aw.WriteFile(filepath.Join(root, "go.mod"), []byte("module orders\n"), 0644, "gomod")
+	aw.WriteFile(filepath.Join(root, "scripts", "run.sh"), []byte("#!/bin/sh\n"), 0755, "script")
+	return aw
+}
+
+var wantArchiveEntries = map[string]archiveEntry{
+	"internal/":        {Mode: os.ModeDir | 0755},
+	"internal/domain/": {Mode: os.ModeDir | 0755},
+	"scripts/":         {Mode: os.ModeDir | 0755},
+	"go.mod":           {Mode: 0644, Data: "module orders\n"},
+	"scripts/run.sh":   {Mode: 0755, Data: "#!/bin/sh\n"},
+}
+
+func TestArchiveWriterTarGz(t *testing.T) {
+	var buf bytes.Buffer
+	if err := archiveFixture(ArchiveTarGz).WriteArchive(&buf); err != nil {
+		t.Fatalf("WriteArchive failed: %v", err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	entries := make(map[string]archiveEntry)
+	var order []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[header.Name] = archiveEntry{Mode: header.FileInfo().Mode(), Data: string(data)}
+		order = append(order, header.Name)
+
+		if !header.ModTime.Equal(now()) {
+			t.Errorf("%s modified at %v, want %v", header.Name, header.ModTime, now())
+		}
+	}
+
+	if !reflect.DeepEqual(entries, wantArchiveEntries) {
+		t.Errorf("entries = %v, want %v", entries, wantArchiveEntries)
+	}
+	if want := []string{"internal/", "internal/domain/", "scripts/", "go.mod", "scripts/run.sh"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("entry order = %v, want directories first %v", order, want)
+	}
+}
+
+func TestArchiveWriterZip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := archiveFixture(ArchiveZip).WriteArchive(&buf); err != nil {
+		t.Fatalf("WriteArchive failed: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := make(map[string]archiveEntry)
+	for _, file := range zr.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[file.Name] = archiveEntry{Mode: file.Mode(), Data: string(data)}
+	}
+
+	if !reflect.DeepEqual(entries, wantArchiveEntries) {
+		t.Errorf("entries = %v, want %v", entries, wantArchiveEntries)
+	}
+}
+
+func TestArchiveFormatFromPath(t *testing.T) {
+	tests := map[string]ArchiveFormat{
+		"orders.tar.gz": ArchiveTarGz,
+		"orders.tgz":    ArchiveTarGz,
+		"orders.zip":    ArchiveZip,
+	}
+	for path, want := range tests {
+		if got, err := ArchiveFormatFromPath(path); err != nil || got != want {
+			t.Errorf("ArchiveFormatFromPath(%s) = %s, %v, want %s", path, got, err, want)
+		}
+	}
+
+	if _, err := ArchiveFormatFromPath("orders.rar"); err == nil {
+		t.Error("ArchiveFormatFromPath accepted .rar")
+	}
+}
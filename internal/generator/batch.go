@@ -0,0 +1,291 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BatchSpec lists projects to generate together, e.g. every service of a
+// monorepo:
+//
+//	defaults:
+//	  module_prefix: github.com/acme
+//	  architecture: hexagonal
+//	  with: [postgres]
+//	projects:
+//	  - name: orders
+//	    with: [postgres, redis]
+//	    variables: {team: payments}
+//	  - name: billing
+//	    architecture: clean
+//	    license: Apache
+type BatchSpec struct {
+	Defaults BatchDefaults  `yaml:"defaults"`
+	Projects []BatchProject `yaml:"projects"`
+}
+
+// BatchDefaults apply to every project of a batch that doesn't set them
+type BatchDefaults struct {
+	BatchProject `yaml:",inline"`
+	// ModulePrefix makes the module path of projects without one
+	// <module_prefix>/<name>
+	ModulePrefix string `yaml:"module_prefix"`
+}
+
+// BatchProject is one project of a batch. Unset fields fall back to the
+// batch defaults, then to the configured defaults.
+type BatchProject struct {
+	Name         string            `yaml:"name"`
+	Module       string            `yaml:"module"`
+	Architecture string            `yaml:"architecture"`
+	License      string            `yaml:"license"`
+	Features     []string          `yaml:"with"`
+	WithDocker   *bool             `yaml:"with_docker"`
+	WithMakefile *bool             `yaml:"with_makefile"`
+	WithGit      *bool             `yaml:"with_git"`
+	Template     string            `yaml:"template"`
+	Variables    map[string]string `yaml:"variables"`
+	// Dir is the directory the project is generated in, relative to the
+	// target directory of the batch
+	Dir string `yaml:"dir"`
+}
+
+// LoadBatchSpec reads a batch spec. Unknown keys are rejected, so a typo
+// doesn't silently generate a project without an option.
+func LoadBatchSpec(path string) (*BatchSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec %s: %w", path, err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var spec BatchSpec
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("failed to parse spec %s: %w", path, err)
+	}
+	if len(spec.Projects) == 0 {
+		return nil, fmt.Errorf("spec %s lists no projects", path)
+	}
+	return &spec, nil
+}
+
+// Configs resolves the configuration of every project, generated under
+// targetDir, falling back to the defaults of configFile. Every project is
+// checked before any is generated.
+func (s *BatchSpec) Configs(targetDir string, configFile *ConfigFile) ([]*Config, error) {
+	var configs []*Config
+	var problems []string
+	seen := make(map[string]string)
+
+	for i, project := range s.Projects {
+		config, err := s.config(project, targetDir, configFile)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("project %d (%s): %v", i+1, project.Name, err))
+			continue
+		}
+
+		path := filepath.Clean(filepath.Join(config.TargetDir, config.ProjectName))
+		if other, ok := seen[path]; ok {
+			problems = append(problems, fmt.Sprintf("project %d (%s): generated into %s like %s", i+1, project.Name, path, other))
+			continue
+		}
+		seen[path] = project.Name
+		configs = append(configs, config)
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid spec:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return configs, nil
+}
+
+// config resolves one project against the batch and configured defaults
+func (s *BatchSpec) config(project BatchProject, targetDir string, configFile *ConfigFile) (*Config, error) {
+	if project.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	defaults := s.Defaults
+	configured := configFile.Defaults
+
+	config := &Config{
+		ProjectName:  project.Name,
+		ModulePath:   project.Module,
+		Architecture: firstNonEmpty(project.Architecture, defaults.Architecture, configured.Architecture, "basic"),
+		License:      firstNonEmpty(project.License, defaults.License, configured.License, "MIT"),
+		TargetDir:    filepath.Join(targetDir, firstNonEmpty(project.Dir, defaults.Dir)),
+		WithDocker:   firstBool(project.WithDocker, defaults.WithDocker, configured.WithDocker),
+		WithMakefile: firstBool(project.WithMakefile, defaults.WithMakefile, configured.WithMakefile),
+		WithGit:      firstBool(project.WithGit, defaults.WithGit, configured.WithGit),
+		AutoYes:      true,
+		Variables:    make(map[string]string),
+	}
+
+	switch {
+	case project.Features != nil:
+		config.Features = project.Features
+	case defaults.Features != nil:
+		config.Features = defaults.Features
+	default:
+		config.Features = configured.Features
+	}
+	config.Features = append([]string(nil), config.Features...)
+
+	for name, value := range defaults.Variables {
+		config.Variables[name] = value
+	}
+	for name, value := range project.Variables {
+		config.Variables[name] = value
+	}
+
+	if config.ModulePath == "" {
+		if prefix := firstNonEmpty(defaults.ModulePrefix, configured.ModulePrefix); prefix != "" {
+			config.ModulePath = strings.TrimSuffix(prefix, "/") + "/" + project.Name
+		} else {
+			config.ModulePath = DetectModulePath(config.TargetDir, project.Name)
+		}
+	}
+
+	if _, ok := LookupArchitecture(config.Architecture); !ok {
+		return nil, fmt.Errorf("unsupported architecture: %s", config.Architecture)
+	}
+	if _, err := ResolveFeatures(config.Features); err != nil {
+		return nil, err
+	}
+	if _, ok := spdxIdentifiers[config.License]; !ok && config.License != "None" {
+		return nil, fmt.Errorf("unsupported license: %s", config.License)
+	}
+	if name := firstNonEmpty(project.Template, defaults.Template); name != "" {
+		template, err := configFile.FindTemplate(name)
+		if err != nil {
+			return nil, err
+		}
+		config.CustomTemplate = template
+	}
+
+	return config, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func firstBool(value, fallback *bool, configured bool) bool {
+	switch {
+	case value != nil:
+		return *value
+	case fallback != nil:
+		return *fallback
+	default:
+		return configured
+	}
+}
+
+// BatchStatus is the outcome of one project of a batch
+type BatchStatus string
+
+const (
+	BatchGenerated BatchStatus = "generated"
+	BatchFailed    BatchStatus = "failed"
+	// BatchCancelled means the project was not generated because the batch
+	// stopped, after a failure with FailFast or an interrupt
+	BatchCancelled BatchStatus = "cancelled"
+)
+
+// BatchResult is the outcome of one project of a batch
+type BatchResult struct {
+	Config   *Config
+	Status   BatchStatus
+	Err      error
+	Duration time.Duration
+}
+
+// BatchOptions control how a batch runs
+type BatchOptions struct {
+	// Jobs is the number of projects generated at the same time
+	Jobs int
+	// FailFast stops the batch at the first failure; projects being
+	// generated are cancelled and rolled back
+	FailFast bool
+}
+
+// RunBatch generates every config with generate, at most options.Jobs at
+// a time, and returns one result per config in the same order
+func RunBatch(ctx context.Context, configs []*Config, options BatchOptions, generate func(context.Context, *Config) error) []BatchResult {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := options.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	results := make([]BatchResult, len(configs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < jobs && w < len(configs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = runBatchProject(ctx, configs[i], generate)
+				if results[i].Status == BatchFailed && options.FailFast {
+					cancel()
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range configs {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			for j := i; j < len(configs); j++ {
+				results[j] = BatchResult{Config: configs[j], Status: BatchCancelled, Err: ctx.Err()}
+			}
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+func runBatchProject(ctx context.Context, config *Config, generate func(context.Context, *Config) error) BatchResult {
+	if err := ctx.Err(); err != nil {
+		return BatchResult{Config: config, Status: BatchCancelled, Err: err}
+	}
+
+	start := time.Now()
+	err := generate(ctx, config)
+	result := BatchResult{Config: config, Status: BatchGenerated, Err: err, Duration: time.Since(start)}
+
+	switch {
+	case err == nil:
+	case errors.Is(err, context.Canceled) && ctx.Err() != nil:
+		// Stopped by the batch rather than failed on its own
+		result.Status = BatchCancelled
+	default:
+		result.Status = BatchFailed
+	}
+	return result
+}
@@ -0,0 +1,207 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeSpec(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "services.yml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBatchSpecConfigs(t *testing.T) {
+	spec, err := LoadBatchSpec(writeSpec(t, `
+defaults:
+  module_prefix: github.com/acme/
+  architecture: hexagonal
+  with: [postgres]
+  with_docker: true
+  variables: {team: platform}
+projects:
+  - name: orders
+    with: [redis]
+    variables: {team: payments}
+  - name: billing
+    module: example.com/billing
+    architecture: clean
+    license: Apache
+    with_docker: false
+    dir: services
+`))
+	if err != nil {
+		t.Fatalf("LoadBatchSpec failed: %v", err)
+	}
+
+	configFile := &ConfigFile{Defaults: DefaultsConfig{License: "BSD", WithMakefile: true}}
+	configs, err := spec.Configs("/work", configFile)
+	if err != nil {
+		t.Fatalf("Configs failed: %v", err)
+	}
+
+	want := []*Config{
+		{
+			ProjectName:  "orders",
+			ModulePath:   "github.com/acme/orders",
+			Architecture: "hexagonal",
+			License:      "BSD",
+			TargetDir:    "/work",
+			WithDocker:   true,
+			WithMakefile: true,
+			AutoYes:      true,
+			Features:     []string{"redis"},
+			Variables:    map[string]string{"team": "payments"},
+		},
+		{
+			ProjectName:  "billing",
+			ModulePath:   "example.com/billing",
+			Architecture: "clean",
+			License:      "Apache",
+			TargetDir:    "/work/services",
+			WithMakefile: true,
+			AutoYes:      true,
+			Features:     []string{"postgres"},
+			Variables:    map[string]string{"team": "platform"},
+		},
+	}
+	if !reflect.DeepEqual(configs, want) {
+		for i := range configs {
+			t.Errorf("config %d = %+v\nwant %+v", i, configs[i], want[i])
+		}
+	}
+}
+
+func TestBatchSpecErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{name: "no projects", spec: "defaults: {license: MIT}\n", want: "lists no projects"},
+		{name: "unknown key", spec: "projects:\n  - name: orders\n    architecure: clean\n", want: "architecure"},
+		{name: "missing name", spec: "projects:\n  - module: example.com/x\n", want: "name is required"},
+		{name: "unknown architecture", spec: "projects:\n  - name: orders\n    architecture: onion\n", want: "unsupported architecture: onion"},
+		{name: "unknown feature", spec: "projects:\n  - name: orders\n    with: [kafka]\n", want: "kafka"},
+		{name: "unknown license", spec: "projects:\n  - name: orders\n    license: WTFPL\n", want: "unsupported license: WTFPL"},
+		{name: "unknown template", spec: "projects:\n  - name: orders\n    template: missing\n", want: "missing not found"},
+		{name: "same directory", spec: "projects:\n  - name: orders\n  - name: orders\n", want: "like orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := LoadBatchSpec(writeSpec(t, tt.spec))
+			if err == nil {
+				_, err = spec.Configs(t.TempDir(), &ConfigFile{})
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	configs := make([]*Config, 10)
+	for i := range configs {
+		configs[i] = &Config{ProjectName: string(rune('a' + i))}
+	}
+
+	var mu sync.Mutex
+	running, most := 0, 0
+	results := RunBatch(context.Background(), configs, BatchOptions{Jobs: 3}, func(ctx context.Context, config *Config) error {
+		mu.Lock()
+		running++
+		most = max(most, running)
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if config.ProjectName == "c" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	if most > 3 {
+		t.Errorf("%d projects ran at the same time, want at most 3", most)
+	}
+	for i, result := range results {
+		if result.Config != configs[i] {
+			t.Fatalf("result %d is for %s, want results in order", i, result.Config.ProjectName)
+		}
+		want := BatchGenerated
+		if result.Config.ProjectName == "c" {
+			want = BatchFailed
+		}
+		if result.Status != want {
+			t.Errorf("%s: status %s, want %s", result.Config.ProjectName, result.Status, want)
+		}
+	}
+}
+
+func TestRunBatchFailFast(t *testing.T) {
+	configs := make([]*Config, 5)
+	for i := range configs {
+		configs[i] = &Config{ProjectName: string(rune('a' + i))}
+	}
+
+	results := RunBatch(context.Background(), configs, BatchOptions{Jobs: 2, FailFast: true}, func(ctx context.Context, config *Config) error {
+		if config.ProjectName == "a" {
+			return errors.New("boom")
+		}
+		// The other project started alongside waits to be cancelled
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	})
+
+	if results[0].Status != BatchFailed {
+		t.Errorf("a: status %s, want %s", results[0].Status, BatchFailed)
+	}
+	for _, result := range results[1:] {
+		if result.Status != BatchCancelled {
+			t.Errorf("%s: status %s, want %s", result.Config.ProjectName, result.Status, BatchCancelled)
+		}
+	}
+}
+
+func TestRunBatchGeneratesProjects(t *testing.T) {
+	targetDir := t.TempDir()
+	spec := &BatchSpec{Projects: []BatchProject{{Name: "orders"}, {Name: "billing", License: "None"}}}
+	configs, err := spec.Configs(targetDir, &ConfigFile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := RunBatch(context.Background(), configs, BatchOptions{Jobs: 2}, func(ctx context.Context, config *Config) error {
+		return generateInto(t, ctx, config, config.TargetDir)
+	})
+	for _, result := range results {
+		if result.Status != BatchGenerated {
+			t.Errorf("%s: %s: %v", result.Config.ProjectName, result.Status, result.Err)
+		}
+	}
+	for _, name := range []string{"orders", "billing"} {
+		if _, err := ReadProjectManifest(filepath.Join(targetDir, name)); err != nil {
+			t.Errorf("%s was not generated: %v", name, err)
+		}
+	}
+}
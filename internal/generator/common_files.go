@@ -2,37 +2,46 @@ package generator
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"strings"
 )
 
 // CommonFileGenerator handles generation of common project files
 type CommonFileGenerator struct {
 	config *Config
 	logger Logger
+	writer Writer
 }
 
 // NewCommonFileGenerator creates a new common file generator
-func NewCommonFileGenerator(config *Config, logger Logger) *CommonFileGenerator {
+func NewCommonFileGenerator(config *Config, logger Logger, writer Writer) *CommonFileGenerator {
 	return &CommonFileGenerator{
 		config: config,
 		logger: logger,
+		writer: writer,
 	}
 }
 
 // GenerateGoMod generates go.mod file
 func (cfg *CommonFileGenerator) GenerateGoMod(projectPath string) error {
-	content := fmt.Sprintf(`module %s
+	content := fmt.Sprintf("module %s\n\ngo 1.21\n", cfg.config.modulePath())
 
-go 1.21
-
-require (
-	github.com/gorilla/mux v1.8.0
-	github.com/lib/pq v1.10.9
-)`, cfg.config.ProjectName)
+	// The starter code only uses the standard library; feature modules and
+	// custom templates add their own requirements
+	modules, err := ResolveFeatures(cfg.config.Features)
+	if err != nil {
+		return err
+	}
+	requires := featureGoRequires(modules)
+	if cfg.config.CustomTemplate != nil {
+		requires = append(requires, cfg.config.CustomTemplate.goModRequires()...)
+	}
+	if len(requires) > 0 {
+		content += fmt.Sprintf("\nrequire (\n\t%s\n)\n", strings.Join(requires, "\n\t"))
+	}
 
 	filePath := filepath.Join(projectPath, "go.mod")
-	return os.WriteFile(filePath, []byte(content), 0644)
+	return cfg.writer.WriteFile(filePath, []byte(content), 0644, "gomod")
 }
 
 // GenerateReadme generates README.md file
@@ -97,7 +106,7 @@ This project is licensed under the %s License.`,
 		cfg.config.License)
 
 	filePath := filepath.Join(projectPath, "README.md")
-	return os.WriteFile(filePath, []byte(content), 0644)
+	return cfg.writer.WriteFile(filePath, []byte(content), 0644, "readme")
 }
 
 // GenerateGitignore generates .gitignore file
@@ -157,5 +166,5 @@ tmp/
 temp/`
 
 	filePath := filepath.Join(projectPath, ".gitignore")
-	return os.WriteFile(filePath, []byte(content), 0644)
+	return cfg.writer.WriteFile(filePath, []byte(content), 0644, "gitignore")
 }
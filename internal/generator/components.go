@@ -0,0 +1,279 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// ComponentKind is a piece of an entity scaffolded by gomake generate
+type ComponentKind string
+
+const (
+	ComponentEntity     ComponentKind = "entity"
+	ComponentRepository ComponentKind = "repository"
+	ComponentService    ComponentKind = "service"
+	ComponentHandler    ComponentKind = "handler"
+)
+
+// ComponentKinds lists the component kinds in the order an entity is
+// scaffolded
+var ComponentKinds = []ComponentKind{ComponentEntity, ComponentRepository, ComponentService, ComponentHandler}
+
+// ComponentLayout tells where an architecture puts the pieces of an entity.
+// File paths are templates with the Component in scope, e.g.
+// internal/core/domain/{{.Component.File}}.go.
+type ComponentLayout struct {
+	Files map[ComponentKind][]PlannedFile
+	// EntityPackage is the name of the package holding entities, e.g.
+	// domain
+	EntityPackage string
+	// Wiring is a template of the code that connects the pieces, printed
+	// after generation since existing files are never edited
+	Wiring string
+}
+
+// Field is a field of a scaffolded entity
+type Field struct {
+	// Name is the Go field name, e.g. CreatedBy
+	Name string
+	// JSON is the JSON key, e.g. created_by
+	JSON string
+	// Type is the Go type, e.g. time.Time
+	Type string
+}
+
+// fieldTypes maps the types accepted in --fields to Go types
+var fieldTypes = map[string]string{
+	"string":    "string",
+	"int":       "int",
+	"int32":     "int32",
+	"int64":     "int64",
+	"uint":      "uint",
+	"float":     "float64",
+	"float32":   "float32",
+	"float64":   "float64",
+	"bool":      "bool",
+	"time":      "time.Time",
+	"time.Time": "time.Time",
+}
+
+// Example returns a Go expression with a valid value for the field, used
+// in generated tests
+func (f Field) Example() string {
+	switch f.Type {
+	case "string":
+		return fmt.Sprintf("%q", "test "+strings.ReplaceAll(f.JSON, "_", " "))
+	case "bool":
+		return "true"
+	case "time.Time":
+		return "time.Now()"
+	case "float32", "float64":
+		return "1.5"
+	default:
+		return "1"
+	}
+}
+
+// Component is the entity scaffolded by gomake generate
+type Component struct {
+	// Name is the exported type name, e.g. OrderItem
+	Name string
+	// Var is the variable name, e.g. orderItem
+	Var string
+	// Receiver is the method receiver name, e.g. o
+	Receiver string
+	// Label is the name in prose, e.g. order item
+	Label string
+	// Article is the indefinite article for Label, a or an
+	Article string
+	// Plural is the plural type name, e.g. OrderItems
+	Plural string
+	// File is the file name stem, e.g. order_item
+	File string
+	// Route is the URL path segment, e.g. order-items
+	Route  string
+	Fields []Field
+
+	// EntityPackage qualifies the entity outside its own package, set
+	// from the architecture's ComponentLayout
+	EntityPackage string
+}
+
+// NewComponent creates a component from an entity name and a --fields
+// spec such as "name:string,email:string,age:int"
+func NewComponent(name, fields string) (*Component, error) {
+	typeName := pascalCase(name)
+	if typeName == "" || !token.IsIdentifier(typeName) {
+		return nil, fmt.Errorf("invalid entity name: %s", name)
+	}
+
+	parsed, err := ParseFields(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	label := strings.ToLower(strings.Join(splitWords(typeName), " "))
+	article := "a"
+	if strings.ContainsAny(label[:1], "aeiou") {
+		article = "an"
+	}
+
+	plural := pluralize(typeName)
+	return &Component{
+		Name:     typeName,
+		Var:      goIdent(typeName),
+		Receiver: strings.ToLower(typeName[:1]),
+		Label:    label,
+		Article:  article,
+		Plural:   plural,
+		File:     snakeCase(typeName),
+		Route:    kebabCase(plural),
+		Fields:   parsed,
+	}, nil
+}
+
+// ParseFields parses a comma separated list of name:type pairs
+func ParseFields(spec string) ([]Field, error) {
+	var fields []Field
+	seen := map[string]bool{"ID": true, "CreatedAt": true}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, typ, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid field %q: expected name:type", part)
+		}
+
+		goType, ok := fieldTypes[strings.TrimSpace(typ)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported type %q for field %s", typ, name)
+		}
+
+		field := Field{Name: pascalCase(name), JSON: snakeCase(name), Type: goType}
+		if field.Name == "" || !token.IsIdentifier(field.Name) {
+			return nil, fmt.Errorf("invalid field name: %s", name)
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("duplicate field %s (id and created_at are added automatically)", name)
+		}
+		seen[field.Name] = true
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// StringFields returns the string fields, which are required by Validate
+func (c *Component) StringFields() []Field {
+	var fields []Field
+	for _, field := range c.Fields {
+		if field.Type == "string" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// HasType reports whether a field has the given Go type, e.g. to import
+// time for time.Time fields
+func (c *Component) HasType(goType string) bool {
+	for _, field := range c.Fields {
+		if field.Type == goType {
+			return true
+		}
+	}
+	return false
+}
+
+// ComponentGenerator scaffolds entities inside an existing project
+// following the conventions of its architecture
+type ComponentGenerator struct {
+	config      *Config
+	projectPath string
+	logger      Logger
+	layout      *ComponentLayout
+	pipeline    *Pipeline
+	recorder    *checksumRecorder
+}
+
+// NewComponentGenerator creates a component generator for the project
+// described by config in projectPath
+func NewComponentGenerator(config *Config, projectPath string, logger Logger, writer Writer) (*ComponentGenerator, error) {
+	spec, ok := LookupArchitecture(config.Architecture)
+	if !ok {
+		return nil, fmt.Errorf("unsupported architecture: %s", config.Architecture)
+	}
+	if spec.Components == nil {
+		return nil, fmt.Errorf("architecture %s does not define component conventions", config.Architecture)
+	}
+
+	templates, err := NewTemplateManager(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := newChecksumRecorder()
+	return &ComponentGenerator{
+		config:      config,
+		projectPath: projectPath,
+		logger:      logger,
+		layout:      spec.Components,
+		pipeline:    NewPipeline(templates, writer, append(DefaultPostProcessors(config), recorder.Record)...),
+		recorder:    recorder,
+	}, nil
+}
+
+// Generate scaffolds a component of the given kind. An entity includes
+// every other kind the architecture supports.
+func (cg *ComponentGenerator) Generate(kind ComponentKind, component *Component) error {
+	kinds := []ComponentKind{kind}
+	if kind == ComponentEntity {
+		kinds = ComponentKinds
+	} else if _, ok := cg.layout.Files[kind]; !ok {
+		return fmt.Errorf("architecture %s has no %s layer", cg.config.Architecture, kind)
+	}
+
+	data := cg.templateData(component)
+	for _, k := range kinds {
+		files, ok := cg.layout.Files[k]
+		if !ok {
+			continue
+		}
+
+		cg.logger.Debug("Generating component", "kind", k, "name", component.Name)
+		if err := cg.pipeline.Render(cg.projectPath, files, data); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", k, err)
+		}
+	}
+
+	return nil
+}
+
+// Wiring renders the code connecting the generated pieces
+func (cg *ComponentGenerator) Wiring(component *Component) (string, error) {
+	if cg.layout.Wiring == "" {
+		return "", nil
+	}
+	return cg.pipeline.Templates().renderString("wiring", cg.layout.Wiring, cg.templateData(component))
+}
+
+// UpdateManifest records the generated files in the project manifest.
+// Projects without a manifest are left alone.
+func (cg *ComponentGenerator) UpdateManifest() error {
+	return updateProjectManifest(cg.projectPath, cg.config, cg.pipeline.Templates(), cg.recorder, nil)
+}
+
+func (cg *ComponentGenerator) templateData(component *Component) *TemplateData {
+	c := *component
+	c.EntityPackage = cg.layout.EntityPackage
+
+	data := NewTemplateData(cg.config)
+	data.Component = &c
+	return data
+}
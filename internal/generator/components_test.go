@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewComponent(t *testing.T) {
+	component, err := NewComponent("order_item", "sku:string, quantity:int,shipped_at:time,gift:bool")
+	if err != nil {
+		t.Fatalf("NewComponent failed: %v", err)
+	}
+
+	want := Component{
+		Name:     "OrderItem",
+		Var:      "orderItem",
+		Receiver: "o",
+		Label:    "order item",
+		Article:  "an",
+		Plural:   "OrderItems",
+		File:     "order_item",
+		Route:    "order-items",
+	}
+	got := *component
+	got.Fields = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewComponent = %+v, want %+v", got, want)
+	}
+
+	wantFields := []Field{
+		{Name: "Sku", JSON: "sku", Type: "string"},
+		{Name: "Quantity", JSON: "quantity", Type: "int"},
+		{Name: "ShippedAt", JSON: "shipped_at", Type: "time.Time"},
+		{Name: "Gift", JSON: "gift", Type: "bool"},
+	}
+	if len(component.Fields) != len(wantFields) {
+		t.Fatalf("got %d fields, want %d", len(component.Fields), len(wantFields))
+	}
+	for i, field := range component.Fields {
+		if field != wantFields[i] {
+			t.Errorf("field %d = %+v, want %+v", i, field, wantFields[i])
+		}
+	}
+}
+
+func TestNewComponentErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		entity string
+		fields string
+		want   string
+	}{
+		{name: "empty name", entity: "", want: "invalid entity name"},
+		{name: "invalid name", entity: "1user", want: "invalid entity name"},
+		{name: "missing type", entity: "user", fields: "name", want: "expected name:type"},
+		{name: "unsupported type", entity: "user", fields: "tags:[]string", want: "unsupported type"},
+		{name: "duplicate field", entity: "user", fields: "name:string,Name:string", want: "duplicate field"},
+		{name: "reserved field", entity: "user", fields: "id:int", want: "duplicate field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewComponent(tt.entity, tt.fields)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewComponent(%q, %q) error = %v, want %q", tt.entity, tt.fields, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestComponentGeneratorMissingLayer(t *testing.T) {
+	log := quietLogger(t)
+
+	cg, err := NewComponentGenerator(&Config{ProjectName: "shop", Architecture: "mvc"}, "shop", log, NewMemoryWriter())
+	if err != nil {
+		t.Fatalf("NewComponentGenerator failed: %v", err)
+	}
+
+	component, _ := NewComponent("product", "name:string")
+	if err := cg.Generate(ComponentService, component); err == nil {
+		t.Error("expected error for the service layer of mvc")
+	}
+}
+
+func TestGeneratedComponentsBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go build of generated projects in short mode")
+	}
+
+	for _, arch := range goldenArchitectures {
+		t.Run(arch, func(t *testing.T) {
+			projectPath := generateOnDisk(t, &Config{
+				ProjectName:  "shop",
+				ModulePath:   "github.com/acme/shop",
+				Architecture: arch,
+				License:      "MIT",
+			})
+
+			config, err := DetectProject(projectPath, "")
+			if err != nil {
+				t.Fatalf("DetectProject failed: %v", err)
+			}
+
+			log := quietLogger(t)
+			writer := NewNoClobberWriter(NewDiskWriter())
+			cg, err := NewComponentGenerator(config, projectPath, log, writer)
+			if err != nil {
+				t.Fatalf("NewComponentGenerator failed: %v", err)
+			}
+
+			component, err := NewComponent("OrderItem", "sku:string,quantity:int,price:float,shipped_at:time")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cg.Generate(ComponentEntity, component); err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+			if conflicts := writer.Conflicts(); len(conflicts) > 0 {
+				t.Fatalf("unexpected conflicts: %+v", conflicts)
+			}
+
+			wiring, err := cg.Wiring(component)
+			if err != nil {
+				t.Fatalf("Wiring failed: %v", err)
+			}
+			if !strings.Contains(wiring, "OrderItem") {
+				t.Errorf("wiring does not mention the component: %q", wiring)
+			}
+
+			for _, result := range writer.Results() {
+				if _, err := os.Stat(result.Path); err != nil {
+					t.Errorf("%s was not written: %v", filepath.Base(result.Path), err)
+				}
+			}
+
+			if err := Verify(projectPath); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
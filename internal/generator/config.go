@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,37 +17,153 @@ type TemplateConfig struct {
 	Files        map[string]string `yaml:"files"`
 	Dependencies []string          `yaml:"dependencies"`
 	Variables    map[string]string `yaml:"variables"`
+
+	// Source is the config file the template was read from
+	Source string `yaml:"-"`
+}
+
+// DefaultsConfig holds default values for project generation flags
+type DefaultsConfig struct {
+	Architecture string `yaml:"architecture"`
+	License      string `yaml:"license"`
+	WithDocker   bool   `yaml:"with_docker"`
+	WithMakefile bool   `yaml:"with_makefile"`
+	WithGit      bool   `yaml:"with_git"`
+	ModulePrefix string `yaml:"module_prefix"`
+	// Features are the feature modules added to every project
+	Features []string `yaml:"with"`
 }
 
+// DefaultKeys lists the configurable defaults in display order
+var DefaultKeys = []string{"architecture", "license", "with_docker", "with_makefile", "with_git", "module_prefix", "with"}
+
+// BuiltinSource is the source reported for defaults not set in any config file
+const BuiltinSource = "built-in"
+
 // ConfigFile represents the gomake configuration file
 type ConfigFile struct {
 	Templates []TemplateConfig `yaml:"templates"`
-	Defaults  struct {
-		Architecture string `yaml:"architecture"`
-		License      string `yaml:"license"`
-		WithDocker   bool   `yaml:"with_docker"`
-		WithMakefile bool   `yaml:"with_makefile"`
-		WithGit      bool   `yaml:"with_git"`
-	} `yaml:"defaults"`
+	Defaults  DefaultsConfig   `yaml:"defaults"`
+
+	// DefaultSources maps each key of DefaultKeys to the config file it was
+	// read from, or BuiltinSource
+	DefaultSources map[string]string `yaml:"-"`
+
+	// setDefaults records which defaults keys the file sets explicitly
+	setDefaults map[string]bool
 }
 
-// LoadConfig loads configuration from .gomake.yml file
-func LoadConfig() (*ConfigFile, error) {
-	configPaths := []string{
+// ConfigPaths returns config file locations in order of precedence:
+// project-local files first, then user-level files
+func ConfigPaths() []string {
+	home := os.Getenv("HOME")
+	return []string{
 		".gomake.yml",
 		".gomake.yaml",
-		filepath.Join(os.Getenv("HOME"), ".gomake.yml"),
-		filepath.Join(os.Getenv("HOME"), ".config", "gomake", "config.yml"),
+		filepath.Join(home, ".gomake.yml"),
+		filepath.Join(home, ".config", "gomake", "config.yml"),
+	}
+}
+
+// LoadConfig loads and merges configuration from all config files.
+// For each default, the first file in ConfigPaths that sets it wins and
+// built-in values fill the rest. Templates are merged by name with the
+// same precedence.
+func LoadConfig() (*ConfigFile, error) {
+	config := getDefaultConfig()
+	config.DefaultSources = make(map[string]string)
+
+	seenTemplates := make(map[string]bool)
+	for _, path := range ConfigPaths() {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		layer, err := loadConfigFromFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range DefaultKeys {
+			if _, done := config.DefaultSources[key]; done || !layer.setDefaults[key] {
+				continue
+			}
+			config.Defaults.set(key, &layer.Defaults)
+			config.DefaultSources[key] = path
+		}
+
+		for _, tc := range layer.Templates {
+			if seenTemplates[tc.Name] {
+				continue
+			}
+			seenTemplates[tc.Name] = true
+			tc.Source = path
+			config.Templates = append(config.Templates, tc)
+		}
+	}
+
+	for _, key := range DefaultKeys {
+		if _, done := config.DefaultSources[key]; !done {
+			config.DefaultSources[key] = BuiltinSource
+		}
+	}
+
+	return config, nil
+}
+
+// Value returns the default for key formatted as a string
+func (d *DefaultsConfig) Value(key string) string {
+	switch key {
+	case "architecture":
+		return d.Architecture
+	case "license":
+		return d.License
+	case "with_docker":
+		return fmt.Sprintf("%v", d.WithDocker)
+	case "with_makefile":
+		return fmt.Sprintf("%v", d.WithMakefile)
+	case "with_git":
+		return fmt.Sprintf("%v", d.WithGit)
+	case "module_prefix":
+		return d.ModulePrefix
+	case "with":
+		return strings.Join(d.Features, ",")
+	}
+	return ""
+}
+
+func (d *DefaultsConfig) set(key string, from *DefaultsConfig) {
+	switch key {
+	case "architecture":
+		d.Architecture = from.Architecture
+	case "license":
+		d.License = from.License
+	case "with_docker":
+		d.WithDocker = from.WithDocker
+	case "with_makefile":
+		d.WithMakefile = from.WithMakefile
+	case "with_git":
+		d.WithGit = from.WithGit
+	case "module_prefix":
+		d.ModulePrefix = from.ModulePrefix
+	case "with":
+		d.Features = from.Features
 	}
+}
 
-	for _, path := range configPaths {
-		if _, err := os.Stat(path); err == nil {
-			return loadConfigFromFile(path)
+// FindTemplate returns the custom template with the given name
+func (c *ConfigFile) FindTemplate(name string) (*TemplateConfig, error) {
+	for i := range c.Templates {
+		if c.Templates[i].Name == name {
+			return &c.Templates[i], nil
 		}
 	}
 
-	// Return default config if no file found
-	return getDefaultConfig(), nil
+	var names []string
+	for _, t := range c.Templates {
+		names = append(names, t.Name)
+	}
+	return nil, fmt.Errorf("custom template %s not found. Available: %v", name, names)
 }
 
 func loadConfigFromFile(path string) (*ConfigFile, error) {
@@ -60,19 +177,28 @@ func loadConfigFromFile(path string) (*ConfigFile, error) {
 		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
 	}
 
+	// Decode defaults again as a map to tell unset keys from zero values
+	var raw struct {
+		Defaults map[string]interface{} `yaml:"defaults"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	config.setDefaults = make(map[string]bool)
+	for key, value := range raw.Defaults {
+		if value != nil && value != "" {
+			config.setDefaults[key] = true
+		}
+	}
+
 	return &config, nil
 }
 
 func getDefaultConfig() *ConfigFile {
 	return &ConfigFile{
 		Templates: []TemplateConfig{},
-		Defaults: struct {
-			Architecture string `yaml:"architecture"`
-			License      string `yaml:"license"`
-			WithDocker   bool   `yaml:"with_docker"`
-			WithMakefile bool   `yaml:"with_makefile"`
-			WithGit      bool   `yaml:"with_git"`
-		}{
+		Defaults: DefaultsConfig{
 			Architecture: "basic",
 			License:      "MIT",
 			WithDocker:   false,
@@ -104,8 +230,8 @@ func SaveDefaultConfig(path string) error {
 				"cmd/server/main.go": "// Custom microservice main file\npackage main\n\nfunc main() {\n\t// TODO: Implement\n}",
 			},
 			Dependencies: []string{
-				"google.golang.org/grpc",
-				"github.com/grpc-ecosystem/grpc-gateway/v2",
+				"google.golang.org/grpc@v1.64.0",
+				"github.com/grpc-ecosystem/grpc-gateway/v2@v2.20.0",
 			},
 			Variables: map[string]string{
 				"service_name": "{{.ProjectName}}",
@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// configDirs points HOME and the working directory at empty temporary
+// directories for the test and returns them
+func configDirs(t *testing.T) (home, project string) {
+	t.Helper()
+
+	home, project = t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return home, project
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		project  string
+		home     string
+		xdg      string
+		want     DefaultsConfig
+		wantFrom map[string]string
+	}{
+		{
+			name:     "no config files",
+			want:     DefaultsConfig{Architecture: "basic", License: "MIT"},
+			wantFrom: map[string]string{"architecture": BuiltinSource, "with_docker": BuiltinSource},
+		},
+		{
+			name:    "project over home over xdg",
+			project: "defaults:\n  architecture: clean\n",
+			home:    "defaults:\n  architecture: mvc\n  license: Apache\n",
+			xdg:     "defaults:\n  architecture: hexagonal\n  license: GPL\n  module_prefix: github.com/acme\n",
+			want: DefaultsConfig{
+				Architecture: "clean",
+				License:      "Apache",
+				ModulePrefix: "github.com/acme",
+			},
+			wantFrom: map[string]string{
+				"architecture":  "project",
+				"license":       "home",
+				"module_prefix": "xdg",
+				"with_docker":   BuiltinSource,
+			},
+		},
+		{
+			name:    "explicit false overrides true",
+			project: "defaults:\n  with_docker: false\n",
+			home:    "defaults:\n  with_docker: true\n  with_git: true\n",
+			want:    DefaultsConfig{Architecture: "basic", License: "MIT", WithGit: true},
+			wantFrom: map[string]string{
+				"with_docker": "project",
+				"with_git":    "home",
+			},
+		},
+		{
+			name:     "empty values are unset",
+			project:  "defaults:\n  license: \"\"\n  with:\n",
+			home:     "defaults:\n  license: BSD\n  with: [postgres, redis]\n",
+			want:     DefaultsConfig{Architecture: "basic", License: "BSD", Features: []string{"postgres", "redis"}},
+			wantFrom: map[string]string{"license": "home", "with": "home"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home, project := configDirs(t)
+			paths := map[string]string{
+				"project": filepath.Join(project, ".gomake.yml"),
+				"home":    filepath.Join(home, ".gomake.yml"),
+				"xdg":     filepath.Join(home, ".config", "gomake", "config.yml"),
+			}
+			for layer, content := range map[string]string{"project": tt.project, "home": tt.home, "xdg": tt.xdg} {
+				if content != "" {
+					writeConfig(t, paths[layer], content)
+				}
+			}
+
+			config, err := LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig failed: %v", err)
+			}
+			if !reflect.DeepEqual(config.Defaults, tt.want) {
+				t.Errorf("Defaults = %+v, want %+v", config.Defaults, tt.want)
+			}
+			for key, layer := range tt.wantFrom {
+				want := BuiltinSource
+				if layer != BuiltinSource {
+					want = paths[layer]
+				}
+				// The project file is found relative to the working directory
+				if layer == "project" {
+					want = ".gomake.yml"
+				}
+				if got := config.DefaultSources[key]; got != want {
+					t.Errorf("%s read from %s, want %s", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigTemplates(t *testing.T) {
+	home, _ := configDirs(t)
+	writeConfig(t, ".gomake.yml", "templates:\n  - name: api\n    description: project\n")
+	writeConfig(t, filepath.Join(home, ".gomake.yml"), "templates:\n  - name: api\n    description: home\n  - name: worker\n")
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	api, err := config.FindTemplate("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.Description != "project" || api.Source != ".gomake.yml" {
+		t.Errorf("api template = %+v, want the project one", api)
+	}
+	if _, err := config.FindTemplate("worker"); err != nil {
+		t.Errorf("templates of lower layers are not merged: %v", err)
+	}
+}
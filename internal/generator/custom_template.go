@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// CustomTemplateGenerator applies a custom template from .gomake.yml
+type CustomTemplateGenerator struct {
+	config   *Config
+	logger   Logger
+	pipeline *Pipeline
+}
+
+// NewCustomTemplateGenerator creates a new custom template generator
+func NewCustomTemplateGenerator(config *Config, logger Logger, pipeline *Pipeline) *CustomTemplateGenerator {
+	return &CustomTemplateGenerator{
+		config:   config,
+		logger:   logger,
+		pipeline: pipeline,
+	}
+}
+
+// Generate creates the directories and files of the custom template
+func (cg *CustomTemplateGenerator) Generate(projectPath string) error {
+	tc := cg.config.CustomTemplate
+	cg.logger.Info("Applying custom template", "template", tc.Name)
+
+	data, err := cg.templateData()
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range tc.Directories {
+		dirPath := filepath.Join(projectPath, dir)
+		cg.logger.Debug("Creating directory", "path", dirPath)
+
+		if err := cg.pipeline.MkdirAll(dirPath); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	for _, path := range tc.UnpinnedDependencies() {
+		cg.logger.Warning("Dependency is not pinned and was left out of go.mod", "dependency", path)
+	}
+
+	// Files may use built-in templates as partials or bases
+	files := make([]PlannedFile, 0, len(tc.Files))
+	for path, text := range tc.Files {
+		files = append(files, PlannedFile{Path: path, Text: text, Source: "custom/" + tc.Name})
+	}
+
+	return cg.pipeline.Render(projectPath, files, data)
+}
+
+// templateData builds template data with the custom template variables.
+// Variable values are templates themselves, e.g. "{{.ProjectName}}", and
+// only defaults: values already set, e.g. with --set, are kept.
+func (cg *CustomTemplateGenerator) templateData() (*TemplateData, error) {
+	data := NewTemplateData(cg.config)
+
+	for name, value := range cg.config.CustomTemplate.Variables {
+		if _, ok := data.Variables[name]; ok {
+			continue
+		}
+		rendered, err := cg.pipeline.Templates().renderString("variable "+name, value, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render variable %s: %w", name, err)
+		}
+		data.Variables[name] = rendered
+	}
+
+	return data, nil
+}
+
+// goModRequires returns go.mod require lines for the pinned template
+// dependencies, given as "path@version" or "path version". Unpinned ones
+// can't be required without a version and are left to go get.
+func (tc *TemplateConfig) goModRequires() []string {
+	var requires []string
+	for _, dep := range tc.Dependencies {
+		if path, version, ok := splitDependency(dep); ok && version != "" {
+			requires = append(requires, fmt.Sprintf("%s %s", path, version))
+		}
+	}
+	return requires
+}
+
+// UnpinnedDependencies returns the template dependencies without a
+// version, which are not added to go.mod
+func (tc *TemplateConfig) UnpinnedDependencies() []string {
+	var paths []string
+	for _, dep := range tc.Dependencies {
+		if path, version, ok := splitDependency(dep); ok && version == "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// splitDependency splits a dependency into its module path and version,
+// which is empty when the dependency isn't pinned
+func splitDependency(dep string) (path, version string, ok bool) {
+	dep = strings.TrimSpace(dep)
+	if dep == "" {
+		return "", "", false
+	}
+	if i := strings.IndexAny(dep, "@ "); i >= 0 {
+		return dep[:i], strings.TrimSpace(dep[i+1:]), true
+	}
+	return dep, "", true
+}
@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCustomTemplate(t *testing.T) {
+	config := &Config{
+		ProjectName:  "orders",
+		Architecture: "basic",
+		License:      "MIT",
+		CustomTemplate: &TemplateConfig{
+			Name:        "microservice",
+			Directories: []string{"api/proto", "deploy"},
+			Files: map[string]string{
+				"deploy/service.yml": "name: {{.ProjectName}}\nport: {{.Variables.port}}\nurl: {{.Variables.url}}\n",
+			},
+			Dependencies: []string{
+				"github.com/go-chi/chi/v5@v5.0.12",
+				"github.com/google/uuid v1.6.0",
+				"github.com/stretchr/testify",
+			},
+			Variables: map[string]string{
+				"port": "8080",
+				"url":  "https://{{.ProjectName}}.acme.dev",
+			},
+		},
+	}
+
+	log := quietLogger(t)
+
+	config.TargetDir = t.TempDir()
+	gen, err := New(config, log)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+	if err := gen.Generate(); err != nil {
+		t.Fatalf("failed to generate project: %v", err)
+	}
+	projectPath := filepath.Join(config.TargetDir, "orders")
+
+	for _, dir := range []string{"api/proto", "deploy"} {
+		if info, err := os.Stat(filepath.Join(projectPath, dir)); err != nil || !info.IsDir() {
+			t.Errorf("directory %s was not created", dir)
+		}
+	}
+
+	service, err := os.ReadFile(filepath.Join(projectPath, "deploy", "service.yml"))
+	if err != nil {
+		t.Fatalf("deploy/service.yml was not rendered: %v", err)
+	}
+	if want := "name: orders\nport: 8080\nurl: https://orders.acme.dev\n"; string(service) != want {
+		t.Errorf("deploy/service.yml = %q, want %q", service, want)
+	}
+
+	goMod, err := os.ReadFile(filepath.Join(projectPath, "go.mod"))
+	if err != nil {
+		t.Fatalf("go.mod was not generated: %v", err)
+	}
+	for _, require := range []string{"github.com/go-chi/chi/v5 v5.0.12", "github.com/google/uuid v1.6.0"} {
+		if !strings.Contains(string(goMod), require) {
+			t.Errorf("go.mod doesn't require %s:\n%s", require, goMod)
+		}
+	}
+	if strings.Contains(string(goMod), "testify") {
+		t.Errorf("go.mod requires the unpinned dependency:\n%s", goMod)
+	}
+
+	if unpinned := config.CustomTemplate.UnpinnedDependencies(); !reflect.DeepEqual(unpinned, []string{"github.com/stretchr/testify"}) {
+		t.Errorf("UnpinnedDependencies() = %v", unpinned)
+	}
+}
+
+func TestCustomTemplateVariablesKeepSetValues(t *testing.T) {
+	config := &Config{
+		ProjectName:  "orders",
+		Architecture: "basic",
+		License:      "MIT",
+		TargetDir:    t.TempDir(),
+		Variables:    map[string]string{"port": "9090"},
+		CustomTemplate: &TemplateConfig{
+			Name:      "microservice",
+			Files:     map[string]string{"deploy/port": "{{.Variables.port}} {{.Variables.host}}"},
+			Variables: map[string]string{"port": "8080", "host": "localhost"},
+		},
+	}
+
+	gen, err := New(config, quietLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+	if err := gen.Generate(); err != nil {
+		t.Fatalf("failed to generate project: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(config.TargetDir, "orders", "deploy", "port"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "9090 localhost"; string(data) != want {
+		t.Errorf("deploy/port = %q, want the set port and the default host %q", data, want)
+	}
+}
@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChangeStatus describes how a generated file differs from the tree on disk
+type ChangeStatus string
+
+const (
+	FileAdded     ChangeStatus = "added"
+	FileModified  ChangeStatus = "modified"
+	FileUnchanged ChangeStatus = "unchanged"
+)
+
+// FileChange is a generated file compared against the existing tree
+type FileChange struct {
+	File     *MemoryFile
+	Status   ChangeStatus
+	Existing []byte
+}
+
+// DiffWriter records project output in memory and compares it against the
+// existing tree on disk. Nothing is written.
+type DiffWriter struct {
+	*MemoryWriter
+}
+
+// NewDiffWriter creates a new diff writer
+func NewDiffWriter() *DiffWriter {
+	return &DiffWriter{
+		MemoryWriter: NewMemoryWriter(),
+	}
+}
+
+// Changes compares every recorded file against disk, sorted by path
+func (dw *DiffWriter) Changes() ([]FileChange, error) {
+	var changes []FileChange
+
+	for _, file := range dw.Files() {
+		existing, err := os.ReadFile(file.Path)
+		switch {
+		case os.IsNotExist(err):
+			changes = append(changes, FileChange{File: file, Status: FileAdded})
+		case err != nil:
+			return nil, fmt.Errorf("failed to read %s: %w", file.Path, err)
+		case bytes.Equal(existing, file.Data):
+			changes = append(changes, FileChange{File: file, Status: FileUnchanged, Existing: existing})
+		default:
+			changes = append(changes, FileChange{File: file, Status: FileModified, Existing: existing})
+		}
+	}
+
+	return changes, nil
+}
+
+// CompareProject renders the project described by config in memory as if
+// it were generated into projectPath and compares every generated file
+// against it. The manifest and original renders in .gomake/ are left out.
+func CompareProject(config *Config, projectPath string) ([]FileChange, error) {
+	writer := NewDiffWriter()
+	if err := renderProject(config, writer, projectPath); err != nil {
+		return nil, err
+	}
+
+	changes, err := writer.Changes()
+	if err != nil {
+		return nil, err
+	}
+
+	gomakeDir := filepath.Join(projectPath, ".gomake") + string(filepath.Separator)
+	var compared []FileChange
+	for _, change := range changes {
+		if !strings.HasPrefix(filepath.Clean(change.File.Path), gomakeDir) {
+			compared = append(compared, change)
+		}
+	}
+	return compared, nil
+}
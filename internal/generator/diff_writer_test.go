@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffWriterChanges(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"same.txt": "same\n", "changed.txt": "old\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dw := NewDiffWriter()
+	dw.WriteFile(filepath.Join(dir, "same.txt"), []byte("same\n"), 0644, "test")
+	dw.WriteFile(filepath.Join(dir, "changed.txt"), []byte("new\n"), 0644, "test")
+	dw.WriteFile(filepath.Join(dir, "sub", "added.txt"), []byte("added\n"), 0644, "test")
+
+	changes, err := dw.Changes()
+	if err != nil {
+		t.Fatalf("Changes failed: %v", err)
+	}
+
+	want := []struct {
+		name     string
+		status   ChangeStatus
+		existing string
+	}{
+		{"changed.txt", FileModified, "old\n"},
+		{"same.txt", FileUnchanged, "same\n"},
+		{filepath.Join("sub", "added.txt"), FileAdded, ""},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d", len(changes), len(want))
+	}
+	for i, w := range want {
+		change := changes[i]
+		if change.File.Path != filepath.Join(dir, w.name) {
+			t.Errorf("change %d is for %s, want %s", i, change.File.Path, w.name)
+			continue
+		}
+		if change.Status != w.status || string(change.Existing) != w.existing {
+			t.Errorf("%s: status %s, existing %q, want %s, %q", w.name, change.Status, change.Existing, w.status, w.existing)
+		}
+	}
+
+	// Nothing is written
+	if _, err := os.Stat(filepath.Join(dir, "sub")); !os.IsNotExist(err) {
+		t.Errorf("DiffWriter touched disk: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "changed.txt")); string(data) != "old\n" {
+		t.Errorf("changed.txt = %q, want it untouched", data)
+	}
+}
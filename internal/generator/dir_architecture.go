@@ -0,0 +1,193 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ArchitectureManifestFile is the manifest file name of a directory-based
+// architecture
+const ArchitectureManifestFile = "manifest.yml"
+
+// ArchitectureManifest describes a directory-based architecture
+type ArchitectureManifest struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Directories []string `yaml:"directories"`
+	Prompts     []Prompt `yaml:"prompts"`
+}
+
+// Prompt asks the user for a template variable
+type Prompt struct {
+	Name    string `yaml:"name"`
+	Message string `yaml:"message"`
+	Default string `yaml:"default"`
+}
+
+// DirArchitecture is an architecture defined by a manifest and a tree of
+// .tmpl files on disk. Template paths are templates themselves, e.g.
+// cmd/{{.ProjectName}}/main.go.tmpl.
+type DirArchitecture struct {
+	name     string
+	dir      string
+	manifest *ArchitectureManifest
+}
+
+// UserArchitecturesDir returns the directory searched for user-defined
+// architectures
+func UserArchitecturesDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "gomake", "architectures")
+}
+
+// LoadArchitectureDir registers every subdirectory of root containing a
+// manifest as an architecture named prefix + manifest name. A missing root
+// is not an error.
+func LoadArchitectureDir(root, prefix string) error {
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read architectures directory %s: %w", root, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(root, entry.Name())
+		if _, err := os.Stat(filepath.Join(dir, ArchitectureManifestFile)); err != nil {
+			continue
+		}
+
+		arch, err := NewDirArchitecture(dir, prefix)
+		if err != nil {
+			return err
+		}
+
+		if _, exists := LookupArchitecture(arch.name); exists {
+			return fmt.Errorf("architecture %s from %s conflicts with an existing architecture", arch.name, dir)
+		}
+
+		RegisterArchitecture(ArchitectureSpec{
+			Name:        arch.name,
+			Description: arch.manifest.Description,
+			Prompts:     arch.manifest.Prompts,
+			Source:      dir,
+			New:         func() Architecture { return arch },
+		})
+	}
+
+	return nil
+}
+
+// NewDirArchitecture loads the architecture in dir. Its name is prefix plus
+// the manifest name, or the directory name when the manifest has none.
+func NewDirArchitecture(dir, prefix string) (*DirArchitecture, error) {
+	manifestPath := filepath.Join(dir, ArchitectureManifestFile)
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", manifestPath, err)
+	}
+
+	var manifest ArchitectureManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", manifestPath, err)
+	}
+
+	name := manifest.Name
+	if name == "" {
+		name = filepath.Base(dir)
+	}
+
+	return &DirArchitecture{
+		name:     prefix + name,
+		dir:      dir,
+		manifest: &manifest,
+	}, nil
+}
+
+func (d *DirArchitecture) GetName() string {
+	return d.name
+}
+
+func (d *DirArchitecture) GetStructure() *ProjectStructure {
+	structure := NewProjectStructure()
+
+	for _, dir := range d.manifest.Directories {
+		structure.AddDirectory(dir)
+	}
+
+	return structure
+}
+
+func (d *DirArchitecture) Files(config *Config) ([]PlannedFile, error) {
+	templates, err := d.templateFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []PlannedFile
+	for _, rel := range templates {
+		source := filepath.Join(d.dir, rel)
+
+		info, err := os.Stat(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", source, err)
+		}
+		text, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", source, err)
+		}
+
+		// Keep scripts executable
+		mode := os.FileMode(0644)
+		if info.Mode().Perm()&0111 != 0 {
+			mode = 0755
+		}
+
+		files = append(files, PlannedFile{
+			Path:   filepath.ToSlash(strings.TrimSuffix(rel, ".tmpl")),
+			Text:   string(text),
+			Mode:   mode,
+			Source: d.name + "/" + filepath.ToSlash(rel),
+		})
+	}
+
+	return files, nil
+}
+
+// templateFiles returns the .tmpl files of the architecture relative to
+// its directory, in sorted order
+func (d *DirArchitecture) templateFiles() ([]string, error) {
+	var templates []string
+
+	err := filepath.WalkDir(d.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(path, ".tmpl") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(d.dir, path)
+		if err != nil {
+			return err
+		}
+		templates = append(templates, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read architecture %s: %w", d.name, err)
+	}
+
+	sort.Strings(templates)
+	return templates, nil
+}
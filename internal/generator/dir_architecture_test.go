@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string, perm os.FileMode) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// unregisterArchitecture removes an architecture registered by a test
+func unregisterArchitecture(name string) {
+	delete(architectures, name)
+	for i, registered := range architectureOrder {
+		if registered == name {
+			architectureOrder = append(architectureOrder[:i], architectureOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+func TestLoadArchitectureDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "ddd")
+
+	writeTestFile(t, filepath.Join(dir, ArchitectureManifestFile), `
+description: Domain-driven design
+directories:
+  - internal/{{.Variables.context}}/domain
+prompts:
+  - name: context
+    default: billing
+`, 0644)
+	writeTestFile(t, filepath.Join(dir, "cmd", "{{.ProjectName}}", "main.go.tmpl"), "package main // {{.Variables.context}}\n", 0644)
+	writeTestFile(t, filepath.Join(dir, "scripts", "run.sh.tmpl"), "#!/bin/sh\necho {{.ProjectName}}\n", 0755)
+	writeTestFile(t, filepath.Join(dir, "README.md"), "not a template\n", 0644)
+
+	if err := LoadArchitectureDir(root, "test-"); err != nil {
+		t.Fatalf("LoadArchitectureDir() error = %v", err)
+	}
+	t.Cleanup(func() { unregisterArchitecture("test-ddd") })
+
+	spec, ok := LookupArchitecture("test-ddd")
+	if !ok {
+		t.Fatal("architecture test-ddd not registered")
+	}
+	if len(spec.Prompts) != 1 || spec.Prompts[0].Default != "billing" {
+		t.Fatalf("unexpected prompts: %+v", spec.Prompts)
+	}
+
+	log := quietLogger(t)
+
+	writer := NewMemoryWriter()
+	config := &Config{
+		ProjectName:  "shop",
+		Architecture: "test-ddd",
+		TargetDir:    "out",
+		License:      "None",
+		Variables:    map[string]string{"context": "orders"},
+	}
+
+	gen, err := NewWithWriter(config, log, writer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gen.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	main, ok := writer.File(filepath.Join("out", "shop", "cmd", "shop", "main.go"))
+	if !ok {
+		t.Fatal("templated path cmd/shop/main.go not generated")
+	}
+	if got := string(main.Data); got != "package main // orders\n" {
+		t.Errorf("main.go = %q", got)
+	}
+
+	script, ok := writer.File(filepath.Join("out", "shop", "scripts", "run.sh"))
+	if !ok || script.Mode != 0755 {
+		t.Errorf("scripts/run.sh should be generated executable, got %+v", script)
+	}
+
+	if _, ok := writer.File(filepath.Join("out", "shop", "README.md")); !ok {
+		t.Error("README.md should still come from the common generator")
+	}
+
+	found := false
+	for _, d := range writer.Dirs() {
+		if d == filepath.Join("out", "shop", "internal", "orders", "domain") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("templated directory internal/orders/domain not created")
+	}
+}
+
+func TestLoadArchitectureDirRejectsConflicts(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "basic", ArchitectureManifestFile), "description: clash\n", 0644)
+
+	if err := LoadArchitectureDir(root, ""); err == nil {
+		t.Fatal("expected error for architecture name clashing with a built-in")
+	}
+}
@@ -2,21 +2,23 @@ package generator
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"strings"
 )
 
 // DockerGenerator handles Docker files generation
 type DockerGenerator struct {
 	config *Config
 	logger Logger
+	writer Writer
 }
 
 // NewDockerGenerator creates a new Docker generator
-func NewDockerGenerator(config *Config, logger Logger) *DockerGenerator {
+func NewDockerGenerator(config *Config, logger Logger, writer Writer) *DockerGenerator {
 	return &DockerGenerator{
 		config: config,
 		logger: logger,
+		writer: writer,
 	}
 }
 
@@ -48,8 +50,8 @@ WORKDIR /app
 # Install dependencies
 RUN apk add --no-cache git
 
-# Copy go mod files
-COPY go.mod go.sum ./
+# Copy go mod files (go.sum only exists once dependencies are added)
+COPY go.mod go.sum* ./
 RUN go mod download
 
 # Copy source code
@@ -79,11 +81,25 @@ CMD ["./main"]
 `, dg.config.ProjectName)
 
 	filePath := filepath.Join(projectPath, "Dockerfile")
-	return os.WriteFile(filePath, []byte(content), 0644)
+	return dg.writer.WriteFile(filePath, []byte(content), 0644, "dockerfile")
 }
 
 func (dg *DockerGenerator) generateDockerCompose(projectPath string) error {
-	content := fmt.Sprintf(`version: '3.8'
+	modules, err := ResolveFeatures(dg.config.Features)
+	if err != nil {
+		return err
+	}
+
+	// Feature modules contribute the services the app depends on
+	var services []ComposeService
+	for _, module := range modules {
+		if module.Services != nil {
+			services = append(services, module.Services(dg.config)...)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(`version: '3.8'
 
 services:
   app:
@@ -92,45 +108,35 @@ services:
       - "8080:8080"
     environment:
       - APP_ENV=development
-      - DB_HOST=postgres
-      - DB_PORT=5432
-      - DB_USER=postgres
-      - DB_PASSWORD=password
-      - DB_NAME=%s_db
-      - REDIS_HOST=redis
-      - REDIS_PORT=6379
-    depends_on:
-      - postgres
-      - redis
-    restart: unless-stopped
-
-  postgres:
-    image: postgres:15-alpine
-    environment:
-      - POSTGRES_USER=postgres
-      - POSTGRES_PASSWORD=password
-      - POSTGRES_DB=%s_db
-    ports:
-      - "5432:5432"
-    volumes:
-      - postgres_data:/var/lib/postgresql/data
-    restart: unless-stopped
+`)
+	for _, service := range services {
+		for _, env := range service.AppEnv {
+			fmt.Fprintf(&b, "      - %s=%s\n", env.Key, env.Value)
+		}
+	}
+	if len(services) > 0 {
+		b.WriteString("    depends_on:\n")
+		for _, service := range services {
+			fmt.Fprintf(&b, "      - %s\n", service.Name)
+		}
+	}
+	b.WriteString("    restart: unless-stopped\n")
 
-  redis:
-    image: redis:7-alpine
-    ports:
-      - "6379:6379"
-    volumes:
-      - redis_data:/data
-    restart: unless-stopped
+	var volumes []string
+	for _, service := range services {
+		fmt.Fprintf(&b, "\n  %s:\n%s", service.Name, service.Definition)
+		volumes = append(volumes, service.Volumes...)
+	}
 
-volumes:
-  postgres_data:
-  redis_data:
-`, dg.config.ProjectName, dg.config.ProjectName)
+	if len(volumes) > 0 {
+		b.WriteString("\nvolumes:\n")
+		for _, volume := range volumes {
+			fmt.Fprintf(&b, "  %s:\n", volume)
+		}
+	}
 
 	filePath := filepath.Join(projectPath, "docker-compose.yml")
-	return os.WriteFile(filePath, []byte(content), 0644)
+	return dg.writer.WriteFile(filePath, []byte(b.String()), 0644, "docker-compose")
 }
 
 func (dg *DockerGenerator) generateDockerignore(projectPath string) error {
@@ -180,5 +186,5 @@ logs/
 `
 
 	filePath := filepath.Join(projectPath, ".dockerignore")
-	return os.WriteFile(filePath, []byte(content), 0644)
+	return dg.writer.WriteFile(filePath, []byte(content), 0644, "dockerignore")
 }
@@ -0,0 +1,192 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EnvVar is an environment variable written to .env
+type EnvVar struct {
+	Key   string
+	Value string
+}
+
+// EnvSection is the group of .env variables of a feature module
+type EnvSection struct {
+	Feature string
+	Vars    []EnvVar
+}
+
+// ComposeService is a docker-compose service the app depends on
+type ComposeService struct {
+	Name string
+	// Definition is the service body in YAML, indented by four spaces
+	Definition string
+	// Volumes lists named volumes the service uses
+	Volumes []string
+	// AppEnv points the app container at the service
+	AppEnv []EnvVar
+}
+
+// MakeTarget is a Makefile target
+type MakeTarget struct {
+	Name     string
+	Help     string
+	Commands []string
+}
+
+// FeatureModule is an optional add-on selected with --with. Each module
+// contributes to the generated project through the same set of hooks.
+type FeatureModule struct {
+	Name        string
+	Description string
+
+	// Requires lists modules enabled along with this one
+	Requires []string
+	// Conflicts lists modules that can't be enabled with this one
+	Conflicts []string
+
+	// Directories and Files are added to the project. File paths and
+	// contents are templates rendered by the pipeline.
+	Directories []string
+	Files       []PlannedFile
+
+	// GoRequires are go.mod requirements as "path version"
+	GoRequires []string
+
+	// Env returns the .env section of the module
+	Env func(config *Config) []EnvVar
+
+	// Services returns the docker-compose services of the module
+	Services func(config *Config) []ComposeService
+
+	// MakeTargets are appended to the Makefile
+	MakeTargets []MakeTarget
+}
+
+var (
+	featureModules = make(map[string]*FeatureModule)
+	featureOrder   []string
+)
+
+// RegisterFeature makes a feature module available by name. It panics if
+// the name is empty or already registered.
+func RegisterFeature(module FeatureModule) {
+	if module.Name == "" {
+		panic("generator: feature module must have a name")
+	}
+	if _, exists := featureModules[module.Name]; exists {
+		panic(fmt.Sprintf("generator: feature module %s registered twice", module.Name))
+	}
+
+	featureModules[module.Name] = &module
+	featureOrder = append(featureOrder, module.Name)
+}
+
+// LookupFeature returns the registered feature module with the given name
+func LookupFeature(name string) (*FeatureModule, bool) {
+	module, ok := featureModules[name]
+	return module, ok
+}
+
+// FeatureModules returns all registered feature modules in registration
+// order
+func FeatureModules() []*FeatureModule {
+	modules := make([]*FeatureModule, 0, len(featureOrder))
+	for _, name := range featureOrder {
+		modules = append(modules, featureModules[name])
+	}
+	return modules
+}
+
+// FeatureNames returns the names of all registered feature modules
+func FeatureNames() []string {
+	return append([]string(nil), featureOrder...)
+}
+
+// ResolveFeatures returns the requested modules plus everything they
+// require, in registration order. Unknown modules and conflicts between
+// the resulting modules are errors.
+func ResolveFeatures(names []string) ([]*FeatureModule, error) {
+	enabled := make(map[string]bool)
+
+	var enable func(name, requiredBy string) error
+	enable = func(name, requiredBy string) error {
+		name = strings.TrimSpace(name)
+		if name == "" || enabled[name] {
+			return nil
+		}
+
+		module, ok := LookupFeature(name)
+		if !ok {
+			if requiredBy != "" {
+				return fmt.Errorf("feature %s requires unknown feature %s", requiredBy, name)
+			}
+			return fmt.Errorf("unknown feature: %s (available: %s)", name, strings.Join(FeatureNames(), ", "))
+		}
+
+		enabled[name] = true
+		for _, required := range module.Requires {
+			if err := enable(required, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, name := range names {
+		if err := enable(name, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	var modules []*FeatureModule
+	for _, name := range featureOrder {
+		if !enabled[name] {
+			continue
+		}
+
+		module := featureModules[name]
+		for _, conflict := range module.Conflicts {
+			if enabled[conflict] {
+				return nil, fmt.Errorf("feature %s conflicts with %s", name, conflict)
+			}
+		}
+		modules = append(modules, module)
+	}
+
+	return modules, nil
+}
+
+// renderFeature creates the directories of module and renders its files
+func renderFeature(pipeline *Pipeline, projectPath string, module *FeatureModule, data *TemplateData) error {
+	for _, dir := range module.Directories {
+		if err := pipeline.MkdirAll(filepath.Join(projectPath, filepath.FromSlash(dir))); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	if err := pipeline.Render(projectPath, module.Files, data); err != nil {
+		return fmt.Errorf("failed to generate %s files: %w", module.Name, err)
+	}
+	return nil
+}
+
+// featureGoRequires returns the go.mod requirements of modules, sorted and
+// without duplicates
+func featureGoRequires(modules []*FeatureModule) []string {
+	seen := make(map[string]bool)
+	var requires []string
+	for _, module := range modules {
+		for _, require := range module.GoRequires {
+			if !seen[require] {
+				seen[require] = true
+				requires = append(requires, require)
+			}
+		}
+	}
+	sort.Strings(requires)
+	return requires
+}
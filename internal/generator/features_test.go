@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// unregisterFeature removes a feature module registered by a test
+func unregisterFeature(name string) {
+	delete(featureModules, name)
+	for i, registered := range featureOrder {
+		if registered == name {
+			featureOrder = append(featureOrder[:i], featureOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+func featureNames(modules []*FeatureModule) []string {
+	var names []string
+	for _, module := range modules {
+		names = append(names, module.Name)
+	}
+	return names
+}
+
+func TestResolveFeatures(t *testing.T) {
+	RegisterFeature(FeatureModule{Name: "test-sqlc", Requires: []string{"postgres"}})
+	RegisterFeature(FeatureModule{Name: "test-mongo", Conflicts: []string{"postgres"}})
+	RegisterFeature(FeatureModule{Name: "test-broken", Requires: []string{"test-missing"}})
+	t.Cleanup(func() {
+		unregisterFeature("test-sqlc")
+		unregisterFeature("test-mongo")
+		unregisterFeature("test-broken")
+	})
+
+	tests := []struct {
+		name    string
+		names   []string
+		want    []string
+		wantErr string
+	}{
+		{name: "none", names: nil, want: nil},
+		{name: "registration order", names: []string{"redis", "postgres"}, want: []string{"postgres", "redis"}},
+		{name: "duplicates and spaces", names: []string{"jwt", " jwt "}, want: []string{"jwt"}},
+		{name: "requires", names: []string{"test-sqlc"}, want: []string{"postgres", "test-sqlc"}},
+		{name: "conflicts", names: []string{"test-mongo", "test-sqlc"}, wantErr: "conflicts with"},
+		{name: "unknown", names: []string{"kafka"}, wantErr: "unknown feature: kafka"},
+		{name: "unknown requirement", names: []string{"test-broken"}, wantErr: "requires unknown feature test-missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modules, err := ResolveFeatures(tt.names)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveFeatures(%v) error = %v, want %q", tt.names, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveFeatures(%v) failed: %v", tt.names, err)
+			}
+			if got := featureNames(modules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveFeatures(%v) = %v, want %v", tt.names, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRejectsUnknownFeatures(t *testing.T) {
+	config := &Config{ProjectName: "myapp", Architecture: "basic", Features: []string{"kafka"}}
+	if _, err := NewWithWriter(config, quietLogger(t), NewMemoryWriter()); err == nil {
+		t.Fatal("expected error for unknown feature")
+	}
+}
+
+// TestGoldenFeatures snapshots a project with every built-in feature module
+func TestGoldenFeatures(t *testing.T) {
+	config := &Config{
+		ProjectName:  "myapp",
+		ModulePath:   "github.com/acme/myapp",
+		Architecture: "basic",
+		TargetDir:    "out",
+		WithDocker:   true,
+		License:      "MIT",
+		AutoYes:      true,
+		Features:     FeatureNames(),
+	}
+
+	got := generateSnapshot(t, config)
+	compareGolden(t, filepath.Join("testdata", "golden", "basic_features.golden"), got)
+}
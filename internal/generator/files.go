@@ -13,6 +13,7 @@ type FileGenerator struct {
 	dockerGen   *DockerGenerator
 	licenseGen  *LicenseGenerator
 	gitGen      *GitGenerator
+	customGen   *CustomTemplateGenerator
 }
 
 // Logger interface for file generator
@@ -24,16 +25,17 @@ type Logger interface {
 	Success(msg string, args ...interface{})
 }
 
-// NewFileGenerator creates a new file generator
-func NewFileGenerator(config *Config, logger Logger) (*FileGenerator, error) {
+// NewFileGenerator creates a new file generator writing through pipeline
+func NewFileGenerator(config *Config, logger Logger, pipeline *Pipeline) (*FileGenerator, error) {
 	return &FileGenerator{
 		config:      config,
 		logger:      logger,
-		commonGen:   NewCommonFileGenerator(config, logger),
-		makefileGen: NewMakefileGenerator(config, logger),
-		dockerGen:   NewDockerGenerator(config, logger),
-		licenseGen:  NewLicenseGenerator(config, logger),
-		gitGen:      NewGitGenerator(config, logger),
+		commonGen:   NewCommonFileGenerator(config, logger, pipeline),
+		makefileGen: NewMakefileGenerator(config, logger, pipeline),
+		dockerGen:   NewDockerGenerator(config, logger, pipeline),
+		licenseGen:  NewLicenseGenerator(config, logger, pipeline),
+		gitGen:      NewGitGenerator(config, logger, pipeline.Writer()),
+		customGen:   NewCustomTemplateGenerator(config, logger, pipeline),
 	}, nil
 }
 
@@ -56,6 +58,15 @@ func (fg *FileGenerator) GenerateCommonFiles(projectPath string) error {
 	return nil
 }
 
+// GenerateTemplateFiles applies the custom template selected in configuration
+func (fg *FileGenerator) GenerateTemplateFiles(projectPath string) error {
+	if fg.config.CustomTemplate == nil {
+		return nil
+	}
+
+	return fg.customGen.Generate(projectPath)
+}
+
 // GenerateOptionalFiles generates optional files based on configuration
 func (fg *FileGenerator) GenerateOptionalFiles(projectPath string) error {
 	fg.logger.Info("Generating optional files")
@@ -79,12 +90,17 @@ func (fg *FileGenerator) GenerateOptionalFiles(projectPath string) error {
 		}
 	}
 
-	// Initialize git repository if requested
-	if fg.config.WithGit {
-		if err := fg.gitGen.Initialize(projectPath); err != nil {
-			return fmt.Errorf("failed to initialize git repository: %w", err)
-		}
+	return nil
+}
+
+// InitializeGit initializes a git repository if requested
+func (fg *FileGenerator) InitializeGit(projectPath string) error {
+	if !fg.config.WithGit {
+		return nil
 	}
 
+	if err := fg.gitGen.Initialize(projectPath); err != nil {
+		return fmt.Errorf("failed to initialize git repository: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,273 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// templateFuncs returns the function library available to every template.
+// include renders another template by name.
+func templateFuncs(include func(name string, data interface{}) (string, error)) template.FuncMap {
+	return template.FuncMap{
+		// Case conversions
+		"camel":  camelCase,
+		"pascal": pascalCase,
+		"snake":  snakeCase,
+		"kebab":  kebabCase,
+		"title":  titleCase,
+		"upper":  strings.ToUpper,
+		"lower":  strings.ToLower,
+
+		// Go helpers
+		"pluralize": pluralize,
+		"goIdent":   goIdent,
+
+		// Values
+		"default": defaultValue,
+		"env":     os.Getenv,
+		"now":     func() time.Time { return now() },
+		"date":    formatDate,
+
+		// Text
+		"indent": indent,
+		"quote":  quote,
+		"join":   join,
+
+		// Project
+		"hasFeature": hasFeature,
+		"include":    include,
+	}
+}
+
+// commonInitialisms are written in upper case in Go identifiers
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DB": true,
+	"DNS": true, "EOF": true, "GRPC": true, "GUID": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "JWT": true, "OS": true,
+	"RPC": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true,
+	"UI": true, "UID": true, "URI": true, "URL": true, "UTF8": true, "UUID": true,
+	"XML": true,
+}
+
+// splitWords splits s into words at separators, case changes and
+// letter/digit boundaries, e.g. "userProfile-HTTPServer2" becomes
+// user, Profile, HTTP, Server2
+func splitWords(s string) []string {
+	var words []string
+	var current []rune
+
+	runes := []rune(s)
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+
+		if len(current) > 0 && unicode.IsUpper(r) {
+			prev := current[len(current)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// fooBar -> foo Bar, HTTPServer -> HTTP Server
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	return words
+}
+
+func capitalize(word string) string {
+	if upper := strings.ToUpper(word); commonInitialisms[upper] {
+		return upper
+	}
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func pascalCase(s string) string {
+	var b strings.Builder
+	for _, word := range splitWords(s) {
+		b.WriteString(capitalize(word))
+	}
+	return b.String()
+}
+
+func camelCase(s string) string {
+	words := splitWords(s)
+	if len(words) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.ToLower(words[0]))
+	for _, word := range words[1:] {
+		b.WriteString(capitalize(word))
+	}
+	return b.String()
+}
+
+func snakeCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "_"))
+}
+
+func kebabCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "-"))
+}
+
+// titleCase upper-cases the first letter of every word, where words are
+// separated by anything but letters, digits and underscores
+func titleCase(s string) string {
+	prev := ' '
+	return strings.Map(func(r rune) rune {
+		separator := !(unicode.IsLetter(prev) || unicode.IsDigit(prev) || prev == '_')
+		prev = r
+		if separator {
+			return unicode.ToTitle(r)
+		}
+		return r
+	}, s)
+}
+
+// irregularPlurals maps singular nouns to their irregular plural
+var irregularPlurals = map[string]string{
+	"child":  "children",
+	"person": "people",
+	"man":    "men",
+	"woman":  "women",
+	"mouse":  "mice",
+	"goose":  "geese",
+	"foot":   "feet",
+	"tooth":  "teeth",
+	"datum":  "data",
+	"index":  "indices",
+}
+
+// pluralize returns the English plural of a singular noun, keeping the
+// case of its first letter
+func pluralize(s string) string {
+	if s == "" {
+		return s
+	}
+
+	lower := strings.ToLower(s)
+	for singular, plural := range irregularPlurals {
+		if !strings.HasSuffix(lower, singular) {
+			continue
+		}
+
+		// Only replace whole words: "AdminPerson" but not "human"
+		stem := s[:len(s)-len(singular)]
+		first := rune(s[len(stem)])
+		if stem != "" && !unicode.IsUpper(first) {
+			continue
+		}
+		if unicode.IsUpper(first) {
+			plural = strings.ToUpper(plural[:1]) + plural[1:]
+		}
+		return stem + plural
+	}
+
+	switch {
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return s[:len(s)-1] + "ies"
+	default:
+		return s + "s"
+	}
+}
+
+// goIdent turns s into a valid unexported Go identifier, e.g. "my-app"
+// becomes myApp and "2fa" becomes _2fa
+func goIdent(s string) string {
+	ident := camelCase(s)
+	if ident == "" {
+		return "_"
+	}
+	if unicode.IsDigit([]rune(ident)[0]) {
+		ident = "_" + ident
+	}
+	if token.IsKeyword(ident) {
+		ident += "_"
+	}
+	return ident
+}
+
+// defaultValue returns value, or def if value is empty
+func defaultValue(def, value interface{}) interface{} {
+	if value == nil {
+		return def
+	}
+	v := reflect.ValueOf(value)
+	if v.IsZero() || ((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0) {
+		return def
+	}
+	return value
+}
+
+// formatDate formats t with a Go reference layout, e.g. date "2006-01-02" now
+func formatDate(layout string, t time.Time) string {
+	return t.Format(layout)
+}
+
+// indent prefixes every non-empty line of s with n spaces
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func quote(s interface{}) string {
+	return fmt.Sprintf("%q", fmt.Sprint(s))
+}
+
+// join joins the elements of a slice with sep
+func join(sep string, list interface{}) (string, error) {
+	if strs, ok := list.([]string); ok {
+		return strings.Join(strs, sep), nil
+	}
+
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: expected a list, got %T", list)
+	}
+
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
+
+// hasFeature reports whether name is one of the enabled project features,
+// e.g. {{if hasFeature "docker" .Features}}
+func hasFeature(name string, features []string) bool {
+	for _, feature := range features {
+		if feature == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"testing"
+)
+
+func TestCaseConversions(t *testing.T) {
+	tests := []struct {
+		in     string
+		camel  string
+		pascal string
+		snake  string
+		kebab  string
+	}{
+		{"user_profile", "userProfile", "UserProfile", "user_profile", "user-profile"},
+		{"UserProfile", "userProfile", "UserProfile", "user_profile", "user-profile"},
+		{"my-app", "myApp", "MyApp", "my_app", "my-app"},
+		{"HTTPServer", "httpServer", "HTTPServer", "http_server", "http-server"},
+		{"user id", "userID", "UserID", "user_id", "user-id"},
+		{"api2Client", "api2Client", "Api2Client", "api2_client", "api2-client"},
+	}
+
+	for _, tt := range tests {
+		if got := camelCase(tt.in); got != tt.camel {
+			t.Errorf("camel(%q) = %q, want %q", tt.in, got, tt.camel)
+		}
+		if got := pascalCase(tt.in); got != tt.pascal {
+			t.Errorf("pascal(%q) = %q, want %q", tt.in, got, tt.pascal)
+		}
+		if got := snakeCase(tt.in); got != tt.snake {
+			t.Errorf("snake(%q) = %q, want %q", tt.in, got, tt.snake)
+		}
+		if got := kebabCase(tt.in); got != tt.kebab {
+			t.Errorf("kebab(%q) = %q, want %q", tt.in, got, tt.kebab)
+		}
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	tests := map[string]string{
+		"user":        "users",
+		"User":        "Users",
+		"address":     "addresses",
+		"box":         "boxes",
+		"match":       "matches",
+		"category":    "categories",
+		"key":         "keys",
+		"person":      "people",
+		"AdminPerson": "AdminPeople",
+		"Child":       "Children",
+		"human":       "humans",
+		"woman":       "women",
+	}
+
+	for in, want := range tests {
+		if got := pluralize(in); got != want {
+			t.Errorf("pluralize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGoIdent(t *testing.T) {
+	tests := map[string]string{
+		"my-app":   "myApp",
+		"2fa":      "_2fa",
+		"type":     "type_",
+		"":         "_",
+		"order.v2": "orderV2",
+	}
+
+	for in, want := range tests {
+		if got := goIdent(in); got != want {
+			t.Errorf("goIdent(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	t.Setenv("GOMAKE_TEST_ENV", "from-env")
+
+	data := NewTemplateData(&Config{
+		ProjectName: "my-app",
+		WithDocker:  true,
+		Variables:   map[string]string{"owner": ""},
+	})
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{{title .ProjectName}}`, "My-App"},
+		{`{{upper "a"}}{{lower "B"}}`, "Ab"},
+		{`{{default "acme" .Variables.owner}}`, "acme"},
+		{`{{default "acme" .ProjectName}}`, "my-app"},
+		{`{{env "GOMAKE_TEST_ENV"}}`, "from-env"},
+		{`{{now | date "2006"}}`, "2025"},
+		{`{{indent 2 "a\n\nb"}}`, "  a\n\n  b"},
+		{`{{quote .ProjectName}}`, `"my-app"`},
+		{`{{join ", " .Features}}`, "docker"},
+		{`{{if hasFeature "docker" .Features}}yes{{end}}{{if hasFeature "git" .Features}}no{{end}}`, "yes"},
+		{`{{include "common/gitignore" . | printf "%.8s"}}`, "# Binari"},
+	}
+
+	tm, err := NewTemplateManagerWithLayers(nil)
+	if err != nil {
+		t.Fatalf("NewTemplateManagerWithLayers() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		got, err := tm.renderString("test", tt.text, data)
+		if err != nil {
+			t.Errorf("render %s: %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("render %s = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -1,16 +1,25 @@
 package generator
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/gomake/pkg/logger"
 )
 
+// now returns the current time; tests replace it for reproducible output
+var now = time.Now
+
 // Config holds the configuration for project generation
 type Config struct {
 	ProjectName  string
+	ModulePath   string
 	Architecture string
 	TargetDir    string
 	WithDocker   bool
@@ -18,96 +27,314 @@ type Config struct {
 	WithGit      bool
 	License      string
 	AutoYes      bool
+
+	// Variables are user-provided template variables, e.g. answers to
+	// architecture prompts
+	Variables map[string]string
+
+	// CustomTemplate is an optional template from .gomake.yml applied on
+	// top of the architecture
+	CustomTemplate *TemplateConfig
+
+	// Features names the feature modules to add, e.g. postgres or redis
+	Features []string
+
+	// Year is the copyright year of license files and headers; zero means
+	// the current year. Re-rendering a project pins it to the original.
+	Year int
+}
+
+// year returns the copyright year of the project
+func (c *Config) year() int {
+	if c.Year != 0 {
+		return c.Year
+	}
+	return now().Year()
 }
 
 // Generator handles project generation
 type Generator struct {
-	config  *Config
-	logger  *logger.Logger
-	arch    Architecture
-	fileGen *FileGenerator
+	config   *Config
+	logger   *logger.Logger
+	arch     Architecture
+	features []*FeatureModule
+	fileGen  *FileGenerator
+	pipeline *Pipeline
+	recorder *checksumRecorder
 }
 
 // Architecture interface defines methods for different architectures
 type Architecture interface {
 	GetName() string
 	GetStructure() *ProjectStructure
-	GenerateFiles(projectPath string, config *Config) error
+	// Files plans the files of the architecture; the pipeline renders them
+	Files(config *Config) ([]PlannedFile, error)
 }
 
-// New creates a new generator instance
+// New creates a new generator instance that writes to disk
 func New(config *Config, logger *logger.Logger) (*Generator, error) {
+	return NewWithWriter(config, logger, NewDiskWriter())
+}
+
+// NewWithWriter creates a new generator instance that writes through writer
+func NewWithWriter(config *Config, logger *logger.Logger, writer Writer) (*Generator, error) {
 	if config == nil {
 		return nil, fmt.Errorf("config cannot be nil")
 	}
+	// A new project has no overrides of its own yet; they are read from
+	// the directory it is generated in
+	return newGenerator(config, logger, writer, config.TargetDir)
+}
 
+// newGenerator creates a generator whose template overrides are read from
+// templateDir, see TemplateLayers
+func newGenerator(config *Config, logger *logger.Logger, writer Writer, templateDir string) (*Generator, error) {
 	if logger == nil {
 		return nil, fmt.Errorf("logger cannot be nil")
 	}
 
+	if writer == nil {
+		return nil, fmt.Errorf("writer cannot be nil")
+	}
+
 	// Create architecture instance
 	arch, err := createArchitecture(config.Architecture)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create architecture: %w", err)
 	}
 
+	// Resolve feature modules and their dependencies
+	features, err := ResolveFeatures(config.Features)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve features: %w", err)
+	}
+
+	// Share one template set across everything rendered
+	templates, err := NewTemplateManager(templateDir)
+	if err != nil {
+		return nil, err
+	}
+	// Record the final content of every file for the manifest
+	recorder := newChecksumRecorder()
+	pipeline := NewPipeline(templates, writer, append(DefaultPostProcessors(config), recorder.Record)...)
+
 	// Create file generator
-	fileGen, err := NewFileGenerator(config, logger)
+	fileGen, err := NewFileGenerator(config, logger, pipeline)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file generator: %w", err)
 	}
 
 	return &Generator{
-		config:  config,
-		logger:  logger,
-		arch:    arch,
-		fileGen: fileGen,
+		config:   config,
+		logger:   logger,
+		arch:     arch,
+		features: features,
+		fileGen:  fileGen,
+		pipeline: pipeline,
+		recorder: recorder,
 	}, nil
 }
 
 // Generate creates the project structure
 func (g *Generator) Generate() error {
-	projectPath := filepath.Join(g.config.TargetDir, g.config.ProjectName)
+	return g.GenerateContext(context.Background())
+}
 
+// GenerateContext creates the project structure, stopping between steps
+// once ctx is cancelled. A new project on disk is staged in a temporary
+// directory next to it and renamed into place when complete, so it is
+// either fully generated or not there at all. In an existing project
+// directory the files are rendered in memory and only written once all
+// of them are, so errors and cancellation leave it untouched.
+func (g *Generator) GenerateContext(ctx context.Context) error {
+	projectPath := filepath.Join(g.config.TargetDir, g.config.ProjectName)
 	g.logger.Info("Creating project directory", "path", projectPath)
 
-	// Create project directory
-	if err := os.MkdirAll(projectPath, 0755); err != nil {
-		return fmt.Errorf("failed to create project directory: %w", err)
+	var err error
+	switch {
+	case !writesToDisk(g.pipeline.Writer()):
+		err = g.build(ctx, projectPath)
+	case dirInUse(projectPath):
+		err = g.buildInPlace(ctx, projectPath)
+	default:
+		err = g.buildStaged(ctx, projectPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	g.logger.Success("Project generated successfully", "path", projectPath)
+	return nil
+}
+
+// build renders the project into projectPath and initializes git
+func (g *Generator) build(ctx context.Context, projectPath string) error {
+	if err := g.render(ctx, projectPath); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Initialize git last so the first commit holds every file
+	return g.fileGen.InitializeGit(projectPath)
+}
+
+// buildInPlace builds the project into the existing directory projectPath.
+// The files are staged in memory and written through the writer only once
+// all of them rendered.
+func (g *Generator) buildInPlace(ctx context.Context, projectPath string) error {
+	if g.config.WithGit {
+		if _, err := exec.LookPath("git"); err != nil {
+			return fmt.Errorf("failed to initialize git repository: %w", err)
+		}
+	}
+
+	writer := g.pipeline.writer
+	staged := newStagedWriter(writer)
+	g.pipeline.writer = staged
+	err := g.renderFiles(ctx, projectPath)
+	g.pipeline.writer = writer
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	// Generate directory structure
-	if err := g.generateStructure(projectPath); err != nil {
-		return fmt.Errorf("failed to generate structure: %w", err)
+	if err := staged.commit(); err != nil {
+		return fmt.Errorf("failed to write project: %w", err)
 	}
+	// The manifest sees which files the writer kept
+	if err := g.writeManifest(projectPath); err != nil {
+		return err
+	}
+	return g.fileGen.InitializeGit(projectPath)
+}
 
-	// Generate architecture-specific files
-	if err := g.arch.GenerateFiles(projectPath, g.config); err != nil {
-		return fmt.Errorf("failed to generate architecture files: %w", err)
+// buildStaged builds the project in a staging directory next to
+// projectPath and renames it into place, removing it on failure
+func (g *Generator) buildStaged(ctx context.Context, projectPath string) (err error) {
+	// Stage next to the project so the rename stays on one filesystem
+	parent := filepath.Dir(projectPath)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", parent, err)
 	}
+	staging, err := os.MkdirTemp(parent, "."+filepath.Base(projectPath)+".gomake-")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if rmErr := os.RemoveAll(staging); rmErr != nil {
+			g.logger.Warning("Failed to remove staging directory", "path", staging, "error", rmErr)
+		}
+	}()
 
-	// Generate common files using FileGenerator
-	if err := g.fileGen.GenerateCommonFiles(projectPath); err != nil {
-		return fmt.Errorf("failed to generate common files: %w", err)
+	// MkdirTemp creates private directories
+	if err := os.Chmod(staging, 0755); err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
 	}
 
-	// Generate optional files using FileGenerator
-	if err := g.fileGen.GenerateOptionalFiles(projectPath); err != nil {
-		return fmt.Errorf("failed to generate optional files: %w", err)
+	g.logger.Debug("Staging project", "path", staging)
+	if err := g.build(ctx, staging); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	g.logger.Success("Project generated successfully", "path", projectPath)
+	// An empty project directory is replaced
+	if err := os.Remove(projectPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace %s: %w", projectPath, err)
+	}
+	if err := os.Rename(staging, projectPath); err != nil {
+		return fmt.Errorf("failed to move project into place: %w", err)
+	}
+	return nil
+}
+
+// dirInUse reports whether path exists and isn't an empty directory
+func dirInUse(path string) bool {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		_, statErr := os.Stat(path)
+		return statErr == nil
+	}
+	return len(entries) > 0
+}
+
+// render writes every file of the project and its manifest through the
+// writer, without touching anything else on disk
+func (g *Generator) render(ctx context.Context, projectPath string) error {
+	if err := g.renderFiles(ctx, projectPath); err != nil {
+		return err
+	}
+
+	// Record how the project was generated
+	return g.writeManifest(projectPath)
+}
+
+// renderFiles writes every file of the project through the writer
+func (g *Generator) renderFiles(ctx context.Context, projectPath string) error {
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"create project directory", func() error { return g.pipeline.MkdirAll(projectPath) }},
+		{"generate structure", func() error { return g.generateStructure(projectPath) }},
+		{"generate architecture files", func() error { return g.generateArchitectureFiles(projectPath) }},
+		{"generate features", func() error { return g.generateFeatures(projectPath) }},
+		{"apply custom template", func() error { return g.fileGen.GenerateTemplateFiles(projectPath) }},
+		{"generate common files", func() error { return g.fileGen.GenerateCommonFiles(projectPath) }},
+		{"generate optional files", func() error { return g.fileGen.GenerateOptionalFiles(projectPath) }},
+	}
+
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := step.run(); err != nil {
+			return fmt.Errorf("failed to %s: %w", step.name, err)
+		}
+	}
+	return nil
+}
+
+// renderProject renders the existing project described by config into
+// projectPath through writer, with the template overrides of the project,
+// quietly and without initializing git
+func renderProject(config *Config, writer Writer, projectPath string) error {
+	quiet := logger.New(false)
+	quiet.SetOutput(io.Discard)
+
+	g, err := newGenerator(config, quiet, writer, projectPath)
+	if err != nil {
+		return err
+	}
+	if err := g.render(context.Background(), projectPath); err != nil {
+		return fmt.Errorf("failed to render project: %w", err)
+	}
 	return nil
 }
 
 func (g *Generator) generateStructure(projectPath string) error {
 	structure := g.arch.GetStructure()
+	data := NewTemplateData(g.config)
 
 	for _, dir := range structure.Directories {
-		dirPath := filepath.Join(projectPath, dir)
+		// Directory names may be templates, e.g. cmd/{{.ProjectName}}
+		rendered, err := g.pipeline.Templates().renderString(dir, dir, data)
+		if err != nil {
+			return fmt.Errorf("failed to render directory %s: %w", dir, err)
+		}
+
+		dirPath := filepath.Join(projectPath, rendered)
 		g.logger.Debug("Creating directory", "path", dirPath)
 
-		if err := os.MkdirAll(dirPath, 0755); err != nil {
+		if err := g.pipeline.MkdirAll(dirPath); err != nil {
 			return fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
 	}
@@ -115,17 +342,85 @@ func (g *Generator) generateStructure(projectPath string) error {
 	return nil
 }
 
+// writeManifest writes the manifest with the checksums of every file
+// generated so far, and their original render for gomake upgrade. It
+// bypasses the post-processors so neither is recorded itself.
+func (g *Generator) writeManifest(projectPath string) error {
+	manifest := NewProjectManifest(g.config)
+	manifest.Files = g.recorder.Files(projectPath)
+	writer := g.pipeline.Writer()
+
+	if writesToDisk(writer) {
+		// Existing files kept by a NoClobberWriter don't hold the generated
+		// content; the manifest itself is always replaced
+		if nw, ok := writer.(*NoClobberWriter); ok {
+			manifest.Files = withoutConflicts(manifest.Files, projectPath, nw.Conflicts())
+			writer = nw.writer
+		}
+		// Generating into an existing project keeps the entries of files
+		// not generated now, such as components
+		if existing, err := ReadProjectManifest(projectPath); err == nil {
+			for _, file := range existing.Files {
+				if _, ok := manifest.File(file.Path); !ok {
+					manifest.Files = append(manifest.Files, file)
+				}
+			}
+			sort.Slice(manifest.Files, func(i, j int) bool {
+				return manifest.Files[i].Path < manifest.Files[j].Path
+			})
+		}
+	}
+	manifest.Sources = manifestSources(g.pipeline.Templates(), g.config, manifest.Files)
+
+	if err := g.recorder.WriteBase(writer, projectPath, manifest.Files); err != nil {
+		return err
+	}
+	return manifest.Write(writer, projectPath)
+}
+
+// withoutConflicts drops the files written as conflicts from files
+func withoutConflicts(files []ManifestFile, projectPath string, conflicts []WriteResult) []ManifestFile {
+	skipped := make(map[string]bool)
+	for _, conflict := range conflicts {
+		if rel, err := filepath.Rel(projectPath, conflict.Path); err == nil {
+			skipped[filepath.ToSlash(rel)] = true
+		}
+	}
+
+	var kept []ManifestFile
+	for _, file := range files {
+		if !skipped[file.Path] {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
+
+func (g *Generator) generateArchitectureFiles(projectPath string) error {
+	files, err := g.arch.Files(g.config)
+	if err != nil {
+		return fmt.Errorf("failed to plan architecture files: %w", err)
+	}
+	return g.pipeline.Render(projectPath, files, NewTemplateData(g.config))
+}
+
+func (g *Generator) generateFeatures(projectPath string) error {
+	data := NewTemplateData(g.config)
+
+	for _, feature := range g.features {
+		g.logger.Info("Adding feature", "feature", feature.Name)
+		if err := renderFeature(g.pipeline, projectPath, feature, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func createArchitecture(archType string) (Architecture, error) {
-	switch archType {
-	case "hexagonal":
-		return NewHexagonalArchitecture(), nil
-	case "clean":
-		return NewCleanArchitecture(), nil
-	case "mvc":
-		return NewMVCArchitecture(), nil
-	case "basic":
-		return NewBasicArchitecture(), nil
-	default:
+	spec, ok := LookupArchitecture(archType)
+	if !ok {
 		return nil, fmt.Errorf("unsupported architecture: %s", archType)
 	}
+	return spec.New(), nil
 }
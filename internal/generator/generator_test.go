@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// entries lists the names in dir
+func entries(t *testing.T, dir string) []string {
+	t.Helper()
+
+	list, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range list {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestGenerateLeavesNothingOnFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    func() context.Context
+		config *Config
+	}{
+		{
+			name:   "unsupported license",
+			ctx:    context.Background,
+			config: &Config{ProjectName: "orders", Architecture: "basic", License: "WTFPL"},
+		},
+		{
+			name: "cancelled",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			config: &Config{ProjectName: "orders", Architecture: "basic", License: "MIT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetDir := t.TempDir()
+			if err := generateInto(t, tt.ctx(), tt.config, targetDir); err == nil {
+				t.Fatal("GenerateContext succeeded")
+			}
+			if names := entries(t, targetDir); len(names) != 0 {
+				t.Errorf("left behind %v", names)
+			}
+		})
+	}
+
+	t.Run("reports cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := generateInto(t, ctx, &Config{ProjectName: "orders", Architecture: "basic"}, t.TempDir())
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("GenerateContext() error = %v, want context.Canceled", err)
+		}
+	})
+}
+
+func TestGenerateReplacesEmptyDirectory(t *testing.T) {
+	targetDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(targetDir, "orders"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := generateInto(t, context.Background(), &Config{ProjectName: "orders", Architecture: "basic", License: "MIT"}, targetDir); err != nil {
+		t.Fatalf("GenerateContext failed: %v", err)
+	}
+	if names := entries(t, targetDir); len(names) != 1 || names[0] != "orders" {
+		t.Errorf("target directory holds %v, want only the project", names)
+	}
+
+	info, err := os.Stat(filepath.Join(targetDir, "orders"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0755 {
+		t.Errorf("project directory mode = %v, want 0755", perm)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "orders", "go.mod")); err != nil {
+		t.Errorf("project was not moved into place: %v", err)
+	}
+}
+
+// interruptedContext is cancelled after its Err was checked n times, as if
+// interrupted halfway through generation
+type interruptedContext struct {
+	context.Context
+	n int
+}
+
+func (c *interruptedContext) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestGenerateExistingDirectoryUntouchedOnFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		license string
+	}{
+		{"unsupported license", context.Background(), "WTFPL"},
+		{"interrupted", &interruptedContext{Context: context.Background(), n: 3}, "MIT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetDir := t.TempDir()
+			projectPath := filepath.Join(targetDir, "orders")
+			if err := os.MkdirAll(projectPath, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(projectPath, "README.md"), []byte("mine\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := generateInto(t, tt.ctx, &Config{ProjectName: "orders", Architecture: "basic", License: tt.license}, targetDir)
+			if err == nil {
+				t.Fatal("GenerateContext succeeded")
+			}
+
+			if names := entries(t, projectPath); len(names) != 1 {
+				t.Errorf("project directory holds %v, want only README.md", names)
+			}
+			if data, _ := os.ReadFile(filepath.Join(projectPath, "README.md")); string(data) != "mine\n" {
+				t.Errorf("README.md = %q, want it untouched", data)
+			}
+		})
+	}
+}
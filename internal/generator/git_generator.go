@@ -2,25 +2,41 @@ package generator
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 )
 
 // GitGenerator handles git repository initialization
 type GitGenerator struct {
 	config *Config
 	logger Logger
+	writer Writer
 }
 
 // NewGitGenerator creates a new git generator
-func NewGitGenerator(config *Config, logger Logger) *GitGenerator {
+func NewGitGenerator(config *Config, logger Logger, writer Writer) *GitGenerator {
 	return &GitGenerator{
 		config: config,
 		logger: logger,
+		writer: writer,
 	}
 }
 
 // Initialize initializes git repository
 func (gg *GitGenerator) Initialize(projectPath string) error {
+	// Git needs a real directory to work in
+	if !writesToDisk(gg.writer) {
+		gg.logger.Info("Skipping git initialization for non-disk output")
+		return nil
+	}
+
+	// Never commit the work in a repository generated into
+	if _, err := os.Stat(filepath.Join(projectPath, ".git")); err == nil {
+		gg.logger.Info("Skipping git initialization, the project is already a repository")
+		return nil
+	}
+
 	gg.logger.Info("Initializing git repository")
 
 	// Initialize git repo
@@ -0,0 +1,205 @@
+package generator
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+var (
+	goldenArchitectures = []string{"hexagonal", "clean", "mvc", "basic"}
+	goldenLicenses      = []string{"MIT", "Apache", "BSD", "GPL", "None"}
+)
+
+func TestMain(m *testing.M) {
+	now = func() time.Time {
+		return time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	// Keep user template overrides and architectures out of the snapshots,
+	// but keep using the real Go build cache
+	if os.Getenv("GOCACHE") == "" {
+		if cacheDir, err := os.UserCacheDir(); err == nil {
+			os.Setenv("GOCACHE", filepath.Join(cacheDir, "go-build"))
+		}
+	}
+	home, err := os.MkdirTemp("", "gomake-home-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv("HOME", home)
+
+	code := m.Run()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
+
+// TestGolden generates every architecture and option combination in memory
+// and compares the result against testdata/golden. Run with -update to
+// regenerate the snapshots after an intended template change. Git only
+// runs on disk, see TestGenerateInitializesGit.
+func TestGolden(t *testing.T) {
+	for _, arch := range goldenArchitectures {
+		for _, docker := range []bool{false, true} {
+			for _, license := range goldenLicenses {
+				config := &Config{
+					ProjectName:  "myapp",
+					ModulePath:   "github.com/acme/myapp",
+					Architecture: arch,
+					TargetDir:    "out",
+					WithDocker:   docker,
+					License:      license,
+					AutoYes:      true,
+				}
+
+				name := fmt.Sprintf("%s_docker-%s_%s", arch, onOff(docker), license)
+				t.Run(name, func(t *testing.T) {
+					got := generateSnapshot(t, config)
+					compareGolden(t, filepath.Join("testdata", "golden", name+".golden"), got)
+				})
+			}
+		}
+	}
+}
+
+func TestGenerateInitializesGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	log := quietLogger(t)
+
+	config := &Config{ProjectName: "myapp", Architecture: "basic", License: "MIT", TargetDir: t.TempDir(), WithGit: true}
+	gen, err := New(config, log)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+	if err := gen.Generate(); err != nil {
+		t.Fatalf("failed to generate project: %v", err)
+	}
+
+	// The files are added even when the commit fails without a git user
+	cmd := exec.Command("git", "ls-files")
+	cmd.Dir = filepath.Join(config.TargetDir, "myapp")
+	files, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git ls-files failed: %v", err)
+	}
+	for _, want := range []string{"go.mod", "LICENSE", "README.md"} {
+		if !strings.Contains("\n"+string(files), "\n"+want+"\n") {
+			t.Errorf("%s is not in the repository:\n%s", want, files)
+		}
+	}
+}
+
+// generateSnapshot runs the generator into memory and serializes the tree
+func generateSnapshot(t *testing.T, config *Config) string {
+	t.Helper()
+
+	log := quietLogger(t)
+
+	writer := NewMemoryWriter()
+	gen, err := NewWithWriter(config, log, writer)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+
+	if err := gen.Generate(); err != nil {
+		t.Fatalf("failed to generate project: %v", err)
+	}
+
+	return snapshot(config.TargetDir, writer)
+}
+
+// snapshot serializes directories and files recorded by writer, relative to
+// root, in a stable human readable form
+func snapshot(root string, writer *MemoryWriter) string {
+	var b strings.Builder
+
+	for _, dir := range writer.Dirs() {
+		rel, _ := filepath.Rel(root, dir)
+		if isBaseObject(rel + "/") {
+			continue
+		}
+		fmt.Fprintf(&b, "-- %s/ --\n", filepath.ToSlash(rel))
+	}
+
+	for _, file := range writer.Files() {
+		rel, _ := filepath.Rel(root, file.Path)
+		if isBaseObject(rel) {
+			continue
+		}
+		fmt.Fprintf(&b, "-- %s (%04o, %s) --\n", filepath.ToSlash(rel), file.Mode.Perm(), file.Source)
+		b.Write(file.Data)
+		if len(file.Data) > 0 && file.Data[len(file.Data)-1] != '\n' {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+
+	return b.String()
+}
+
+// isBaseObject reports whether a snapshot path is an original render kept
+// for gomake upgrade; they repeat the files of the snapshot
+func isBaseObject(rel string) bool {
+	return strings.Contains(filepath.ToSlash(rel), "/"+ProjectBaseDir+"/")
+}
+
+func compareGolden(t *testing.T, path, got string) {
+	t.Helper()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run go test -update to create it): %v", err)
+	}
+
+	if got != string(want) {
+		t.Errorf("output differs from %s (run go test -update if the change is intended)\n%s",
+			path, firstDifference(string(want), got))
+	}
+}
+
+// firstDifference describes the first line where want and got diverge
+func firstDifference(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n  want: %q\n  got:  %q", i+1, w, g)
+		}
+	}
+	return ""
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
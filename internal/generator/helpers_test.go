@@ -0,0 +1,42 @@
+package generator
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/gomake/pkg/logger"
+)
+
+// quietLogger returns a logger that discards its output
+func quietLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+
+	log := logger.New(false)
+	log.SetOutput(io.Discard)
+	return log
+}
+
+// generateInto runs GenerateContext for config into targetDir
+func generateInto(t *testing.T, ctx context.Context, config *Config, targetDir string) error {
+	t.Helper()
+
+	config.TargetDir = targetDir
+	gen, err := New(config, quietLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+	return gen.GenerateContext(ctx)
+}
+
+// generateOnDisk generates config into a temporary directory and returns
+// the project path
+func generateOnDisk(t *testing.T, config *Config) string {
+	t.Helper()
+
+	if err := generateInto(t, context.Background(), config, t.TempDir()); err != nil {
+		t.Fatalf("failed to generate project: %v", err)
+	}
+	return filepath.Join(config.TargetDir, config.ProjectName)
+}
@@ -2,22 +2,22 @@ package generator
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-	"time"
 )
 
 // LicenseGenerator handles license file generation
 type LicenseGenerator struct {
 	config *Config
 	logger Logger
+	writer Writer
 }
 
 // NewLicenseGenerator creates a new license generator
-func NewLicenseGenerator(config *Config, logger Logger) *LicenseGenerator {
+func NewLicenseGenerator(config *Config, logger Logger, writer Writer) *LicenseGenerator {
 	return &LicenseGenerator{
 		config: config,
 		logger: logger,
+		writer: writer,
 	}
 }
 
@@ -26,7 +26,7 @@ func (lg *LicenseGenerator) Generate(projectPath string) error {
 	lg.logger.Info("Generating license file", "license", lg.config.License)
 
 	var content string
-	year := time.Now().Year()
+	year := lg.config.year()
 
 	switch lg.config.License {
 	case "MIT":
@@ -42,7 +42,7 @@ func (lg *LicenseGenerator) Generate(projectPath string) error {
 	}
 
 	filePath := filepath.Join(projectPath, "LICENSE")
-	return os.WriteFile(filePath, []byte(content), 0644)
+	return lg.writer.WriteFile(filePath, []byte(content), 0644, "license/"+lg.config.License)
 }
 
 func (lg *LicenseGenerator) generateMITLicense(year int) string {
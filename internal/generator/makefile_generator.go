@@ -2,29 +2,50 @@ package generator
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"strings"
 )
 
 // MakefileGenerator handles Makefile generation
 type MakefileGenerator struct {
 	config *Config
 	logger Logger
+	writer Writer
 }
 
 // NewMakefileGenerator creates a new Makefile generator
-func NewMakefileGenerator(config *Config, logger Logger) *MakefileGenerator {
+func NewMakefileGenerator(config *Config, logger Logger, writer Writer) *MakefileGenerator {
 	return &MakefileGenerator{
 		config: config,
 		logger: logger,
+		writer: writer,
 	}
 }
 
 // Generate creates a Makefile
 func (mg *MakefileGenerator) Generate(projectPath string) error {
+	modules, err := ResolveFeatures(mg.config.Features)
+	if err != nil {
+		return err
+	}
+
+	// Feature modules append their own targets
+	phony := []string{"help", "build", "run", "test", "clean", "lint", "fmt", "install"}
+	var targets strings.Builder
+	for _, module := range modules {
+		for _, target := range module.MakeTargets {
+			phony = append(phony, target.Name)
+			fmt.Fprintf(&targets, "%s: ## %s\n", target.Name, target.Help)
+			for _, command := range target.Commands {
+				fmt.Fprintf(&targets, "\t%s\n", command)
+			}
+			targets.WriteString("\n")
+		}
+	}
+
 	content := fmt.Sprintf(`# %s Makefile
 
-.PHONY: help build run test clean lint fmt install
+.PHONY: %s
 
 APP_NAME=%s
 VERSION?=$(shell git describe --tags --always --dirty 2>/dev/null || echo "dev")
@@ -86,9 +107,9 @@ setup: install-tools deps ## Setup development environment
 
 all: fmt lint test build ## Run all checks and build
 
-.DEFAULT_GOAL := help
-`, mg.config.ProjectName, mg.config.ProjectName)
+%s.DEFAULT_GOAL := help
+`, mg.config.ProjectName, strings.Join(phony, " "), mg.config.ProjectName, targets.String())
 
 	filePath := filepath.Join(projectPath, "Makefile")
-	return os.WriteFile(filePath, []byte(content), 0644)
+	return mg.writer.WriteFile(filePath, []byte(content), 0644, "makefile")
 }
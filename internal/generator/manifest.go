@@ -0,0 +1,428 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectManifestFile is where a generated project records how it was
+// generated, relative to the project
+const ProjectManifestFile = ".gomake/manifest.yml"
+
+// ProjectBaseDir holds the original render of every generated file, named
+// by its checksum, relative to the project. gomake upgrade merges template
+// changes against it.
+const ProjectBaseDir = ".gomake/base"
+
+// ProjectManifest records how a project was generated: the gomake version,
+// the options, the templates used and a checksum of every generated file,
+// so later commands know the layout and which files were modified since
+type ProjectManifest struct {
+	Version      string            `yaml:"version"`
+	GeneratedAt  time.Time         `yaml:"generated_at"`
+	Project      string            `yaml:"project"`
+	Module       string            `yaml:"module"`
+	Architecture string            `yaml:"architecture"`
+	Options      ManifestOptions   `yaml:"options"`
+	Variables    map[string]string `yaml:"variables,omitempty"`
+	Sources      []ManifestSource  `yaml:"sources"`
+	Files        []ManifestFile    `yaml:"files"`
+}
+
+// ManifestOptions are the generation options recorded in the manifest
+type ManifestOptions struct {
+	Docker   bool     `yaml:"docker"`
+	Makefile bool     `yaml:"makefile"`
+	Git      bool     `yaml:"git"`
+	License  string   `yaml:"license"`
+	Features []string `yaml:"features,omitempty"`
+	// Template names the custom template from .gomake.yml, if any
+	Template string `yaml:"template,omitempty"`
+}
+
+// ManifestSource is a template source a project was generated from. The
+// built-in templates are pinned by the gomake version, overrides and
+// user-defined templates by a hash of their content.
+type ManifestSource struct {
+	Name    string `yaml:"name"`
+	Origin  string `yaml:"origin"`
+	Version string `yaml:"version,omitempty"`
+	Hash    string `yaml:"sha256,omitempty"`
+}
+
+// ManifestFile is a generated file and the checksum of its content
+type ManifestFile struct {
+	Path   string `yaml:"path"`
+	Source string `yaml:"source"`
+	Hash   string `yaml:"sha256"`
+}
+
+// builtinSource names the built-in templates in the manifest
+const builtinSource = "gomake"
+
+// NewProjectManifest creates a manifest for config without any files
+func NewProjectManifest(config *Config) *ProjectManifest {
+	manifest := &ProjectManifest{
+		Version:      Version,
+		GeneratedAt:  now().UTC(),
+		Project:      config.ProjectName,
+		Module:       config.modulePath(),
+		Architecture: config.Architecture,
+		Variables:    make(map[string]string),
+	}
+	manifest.SetOptions(config)
+
+	for name, value := range config.Variables {
+		manifest.Variables[name] = value
+	}
+	return manifest
+}
+
+// ReadProjectManifest reads the manifest of the project in projectPath. It
+// returns an error satisfying os.IsNotExist when the project has none.
+func ReadProjectManifest(projectPath string) (*ProjectManifest, error) {
+	path := filepath.Join(projectPath, filepath.FromSlash(ProjectManifestFile))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseProjectManifest(path, data)
+}
+
+func parseProjectManifest(path string, data []byte) (*ProjectManifest, error) {
+	var manifest ProjectManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// Write writes the manifest into projectPath through writer
+func (m *ProjectManifest) Write(writer Writer, projectPath string) error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	header := "# Generated by gomake; records how this project was generated.\n"
+	path := filepath.Join(projectPath, filepath.FromSlash(ProjectManifestFile))
+	if err := writer.WriteFile(path, append([]byte(header), data...), 0644, "manifest"); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// SetOptions records the generation options of config. The custom
+// template is kept when config has none, as it can't be detected later.
+func (m *ProjectManifest) SetOptions(config *Config) {
+	m.Options = ManifestOptions{
+		Docker:   config.WithDocker,
+		Makefile: config.WithMakefile,
+		Git:      config.WithGit,
+		License:  config.License,
+		Features: append([]string(nil), config.Features...),
+		Template: m.Options.Template,
+	}
+	if config.CustomTemplate != nil {
+		m.Options.Template = config.CustomTemplate.Name
+	}
+}
+
+// Config returns the configuration the project was generated with
+func (m *ProjectManifest) Config(targetDir string) *Config {
+	config := &Config{
+		ProjectName:  m.Project,
+		ModulePath:   m.Module,
+		Architecture: m.Architecture,
+		TargetDir:    targetDir,
+		WithDocker:   m.Options.Docker,
+		WithMakefile: m.Options.Makefile,
+		WithGit:      m.Options.Git,
+		License:      m.Options.License,
+		AutoYes:      true,
+		Variables:    make(map[string]string),
+		Features:     append([]string(nil), m.Options.Features...),
+	}
+	if !m.GeneratedAt.IsZero() {
+		config.Year = m.GeneratedAt.Year()
+	}
+	for name, value := range m.Variables {
+		config.Variables[name] = value
+	}
+	return config
+}
+
+// File returns the recorded file with the given slash-separated path
+func (m *ProjectManifest) File(path string) (ManifestFile, bool) {
+	for _, file := range m.Files {
+		if file.Path == path {
+			return file, true
+		}
+	}
+	return ManifestFile{}, false
+}
+
+// ReadFile returns the current content of a recorded file, or nil when it
+// was deleted
+func (m *ProjectManifest) ReadFile(projectPath string, file ManifestFile) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, filepath.FromSlash(file.Path)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Path, err)
+	}
+	return data, nil
+}
+
+// Modified reports whether a recorded file differs from its generated
+// content. Deleted files count as modified.
+func (m *ProjectManifest) Modified(projectPath string, file ManifestFile) (bool, error) {
+	data, err := m.ReadFile(projectPath, file)
+	if err != nil {
+		return false, err
+	}
+	return data == nil || checksum(data) != file.Hash, nil
+}
+
+// merge records files and sources, replacing entries with the same path
+// or name
+func (m *ProjectManifest) merge(files []ManifestFile, sources []ManifestSource) {
+	for _, file := range files {
+		replaced := false
+		for i := range m.Files {
+			if m.Files[i].Path == file.Path {
+				m.Files[i], replaced = file, true
+			}
+		}
+		if !replaced {
+			m.Files = append(m.Files, file)
+		}
+	}
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].Path < m.Files[j].Path
+	})
+
+	for _, source := range sources {
+		replaced := false
+		for i := range m.Sources {
+			if m.Sources[i].Name == source.Name {
+				m.Sources[i], replaced = source, true
+			}
+		}
+		if !replaced {
+			m.Sources = append(m.Sources, source)
+		}
+	}
+}
+
+// checksum returns the hex SHA-256 of data
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// baseObjectPath returns the path of the original render with the given
+// checksum
+func baseObjectPath(projectPath, hash string) string {
+	return filepath.Join(projectPath, filepath.FromSlash(ProjectBaseDir), hash)
+}
+
+// ReadBase returns the original render of a recorded file. It returns an
+// error satisfying os.IsNotExist when it wasn't kept.
+func ReadBase(projectPath string, file ManifestFile) ([]byte, error) {
+	return os.ReadFile(baseObjectPath(projectPath, file.Hash))
+}
+
+// checksumRecorder is a post-processor recording the final content of
+// every file written through a pipeline
+type checksumRecorder struct {
+	files map[string]ManifestFile
+	data  map[string][]byte
+}
+
+func newChecksumRecorder() *checksumRecorder {
+	return &checksumRecorder{
+		files: make(map[string]ManifestFile),
+		data:  make(map[string][]byte),
+	}
+}
+
+// Record records file; it runs after the other post-processors
+func (cr *checksumRecorder) Record(file *OutputFile) error {
+	hash := checksum(file.Data)
+	cr.files[filepath.Clean(file.Path)] = ManifestFile{
+		Path:   filepath.Clean(file.Path),
+		Source: file.Source,
+		Hash:   hash,
+	}
+	cr.data[hash] = append([]byte(nil), file.Data...)
+	return nil
+}
+
+// WriteBase writes the original render of files into the base directory
+// of projectPath through writer
+func (cr *checksumRecorder) WriteBase(writer Writer, projectPath string, files []ManifestFile) error {
+	for _, file := range files {
+		data, ok := cr.data[file.Hash]
+		if !ok {
+			continue
+		}
+		if err := writer.WriteFile(baseObjectPath(projectPath, file.Hash), data, 0644, "base"); err != nil {
+			return fmt.Errorf("failed to write original render of %s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
+// Files returns the recorded files inside projectPath with paths relative
+// to it, sorted by path
+func (cr *checksumRecorder) Files(projectPath string) []ManifestFile {
+	var files []ManifestFile
+	for path, file := range cr.files {
+		rel, err := filepath.Rel(projectPath, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		file.Path = filepath.ToSlash(rel)
+		files = append(files, file)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+// manifestSources returns the sources of files: the built-in templates
+// pinned by version and every other template pinned by its hash
+func manifestSources(templates *TemplateManager, config *Config, files []ManifestFile) []ManifestSource {
+	sources := []ManifestSource{{Name: builtinSource, Origin: EmbeddedLayer, Version: Version}}
+	seen := map[string]bool{}
+
+	for _, file := range files {
+		if seen[file.Source] {
+			continue
+		}
+		seen[file.Source] = true
+
+		if source, ok := templateSource(templates, config, file.Source); ok {
+			sources = append(sources, source)
+		}
+	}
+
+	sort.SliceStable(sources[1:], func(i, j int) bool {
+		return sources[1+i].Name < sources[1+j].Name
+	})
+	return sources
+}
+
+// templateSource describes the template named name unless it is built in
+func templateSource(templates *TemplateManager, config *Config, name string) (ManifestSource, bool) {
+	if origin, err := templates.Origin(name); err == nil {
+		if origin.Layer == EmbeddedLayer {
+			return ManifestSource{}, false
+		}
+		text, _ := templates.RawTemplate(name)
+		return ManifestSource{Name: name, Origin: origin.Layer, Hash: checksum([]byte(text))}, true
+	}
+
+	if tc := config.CustomTemplate; tc != nil && name == "custom/"+tc.Name {
+		return ManifestSource{Name: name, Origin: "custom", Hash: customTemplateHash(tc)}, true
+	}
+
+	spec, ok := LookupArchitecture(config.Architecture)
+	if ok && spec.Source != "" && strings.HasPrefix(name, spec.Name+"/") {
+		rel := strings.TrimPrefix(name, spec.Name+"/")
+		data, err := os.ReadFile(filepath.Join(spec.Source, filepath.FromSlash(rel)))
+		if err != nil {
+			return ManifestSource{}, false
+		}
+		return ManifestSource{
+			Name:    name,
+			Origin:  "architecture",
+			Version: pinnedCommit(spec.Name),
+			Hash:    checksum(data),
+		}, true
+	}
+
+	// Files generated in Go code come with gomake itself
+	return ManifestSource{}, false
+}
+
+// customTemplateHash hashes the files of a custom template in path order
+func customTemplateHash(tc *TemplateConfig) string {
+	paths := make([]string, 0, len(tc.Files))
+	for path := range tc.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var b strings.Builder
+	for _, path := range paths {
+		fmt.Fprintf(&b, "%s\x00%s\x00", path, tc.Files[path])
+	}
+	return checksum([]byte(b.String()))
+}
+
+// pinnedCommit returns the commit of the template source an architecture
+// named <source>/<name> was loaded from, if it is pinned in the lockfile
+func pinnedCommit(architecture string) string {
+	sourceName, _, ok := strings.Cut(architecture, "/")
+	if !ok {
+		return ""
+	}
+
+	sources, err := DefaultSourceManager().Sources()
+	if err != nil {
+		return ""
+	}
+	for _, source := range sources {
+		if source.Name == sourceName {
+			return source.Commit
+		}
+	}
+	return ""
+}
+
+// updateProjectManifest records the files rendered through recorder in
+// the manifest of the project, if it has one, and changes its options with
+// setOptions unless nil. Only files whose content on disk is the generated
+// one are recorded, so files left untouched by a NoClobberWriter keep
+// their entries.
+func updateProjectManifest(projectPath string, config *Config, templates *TemplateManager, recorder *checksumRecorder, setOptions func(*ManifestOptions)) error {
+	manifest, err := ReadProjectManifest(projectPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var files []ManifestFile
+	for _, file := range recorder.Files(projectPath) {
+		if modified, err := manifest.Modified(projectPath, file); err != nil || modified {
+			continue
+		}
+		files = append(files, file)
+	}
+
+	writer := NewDiskWriter()
+	if err := recorder.WriteBase(writer, projectPath, files); err != nil {
+		return err
+	}
+
+	if setOptions != nil {
+		setOptions(&manifest.Options)
+	}
+	manifest.merge(files, manifestSources(templates, config, files)[1:])
+	return manifest.Write(writer, projectPath)
+}
@@ -0,0 +1,251 @@
+package generator
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProjectManifest(t *testing.T) {
+	projectPath := generateOnDisk(t, &Config{
+		ProjectName:  "orders",
+		ModulePath:   "github.com/acme/orders",
+		Architecture: "clean",
+		WithDocker:   true,
+		License:      "Apache",
+		Features:     []string{"jwt"},
+		Variables:    map[string]string{"team": "payments"},
+	})
+
+	manifest, err := ReadProjectManifest(projectPath)
+	if err != nil {
+		t.Fatalf("ReadProjectManifest failed: %v", err)
+	}
+
+	if manifest.Version != Version || manifest.Architecture != "clean" || manifest.Module != "github.com/acme/orders" {
+		t.Errorf("unexpected manifest header: %+v", manifest)
+	}
+	wantOptions := ManifestOptions{Docker: true, License: "Apache", Features: []string{"jwt"}}
+	if !reflect.DeepEqual(manifest.Options, wantOptions) {
+		t.Errorf("Options = %+v, want %+v", manifest.Options, wantOptions)
+	}
+	if manifest.Variables["team"] != "payments" {
+		t.Errorf("Variables = %v", manifest.Variables)
+	}
+	if len(manifest.Sources) == 0 || manifest.Sources[0].Name != builtinSource || manifest.Sources[0].Version != Version {
+		t.Errorf("Sources = %+v", manifest.Sources)
+	}
+
+	// Every generated file is recorded with its content on disk
+	var onDisk []string
+	err = filepath.WalkDir(projectPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(projectPath, path)
+		if rel = filepath.ToSlash(rel); !strings.HasPrefix(rel, ".gomake/") {
+			onDisk = append(onDisk, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Files) != len(onDisk) {
+		t.Fatalf("manifest records %d files, %d on disk", len(manifest.Files), len(onDisk))
+	}
+	for _, path := range onDisk {
+		file, ok := manifest.File(path)
+		if !ok {
+			t.Errorf("%s is not recorded", path)
+			continue
+		}
+		if modified, err := manifest.Modified(projectPath, file); err != nil || modified {
+			t.Errorf("%s: modified = %v, %v; want unmodified", path, modified, err)
+		}
+		if _, err := ReadBase(projectPath, file); err != nil {
+			t.Errorf("%s: original render not kept: %v", path, err)
+		}
+	}
+
+	file, _ := manifest.File("pkg/auth/jwt.go")
+	if err := os.WriteFile(filepath.Join(projectPath, "pkg", "auth", "jwt.go"), []byte("package auth\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if modified, _ := manifest.Modified(projectPath, file); !modified {
+		t.Error("edited file is not reported as modified")
+	}
+}
+
+func TestManifestUpdatedByAdd(t *testing.T) {
+	projectPath := generateOnDisk(t, &Config{
+		ProjectName:  "orders",
+		Architecture: "basic",
+		License:      "MIT",
+		Features:     []string{"postgres"},
+	})
+
+	config, err := DetectProject(projectPath, "")
+	if err != nil {
+		t.Fatalf("DetectProject failed: %v", err)
+	}
+	if !reflect.DeepEqual(config.Features, []string{"postgres"}) {
+		t.Errorf("Features = %v, want the recorded [postgres]", config.Features)
+	}
+
+	log := quietLogger(t)
+	adder, err := NewAdder(config, projectPath, log, NewNoClobberWriter(NewDiskWriter()))
+	if err != nil {
+		t.Fatalf("NewAdder failed: %v", err)
+	}
+	added, err := adder.AddFeature("redis")
+	if err != nil {
+		t.Fatalf("AddFeature failed: %v", err)
+	}
+	if len(added) != 1 {
+		t.Errorf("AddFeature added %v, want only redis", featureNames(added))
+	}
+	if err := adder.UpdateManifest(); err != nil {
+		t.Fatalf("UpdateManifest failed: %v", err)
+	}
+
+	manifest, err := ReadProjectManifest(projectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(manifest.Options.Features, []string{"postgres", "redis"}) {
+		t.Errorf("Features = %v", manifest.Options.Features)
+	}
+	if _, ok := manifest.File("pkg/cache/redis.go"); !ok {
+		t.Error("added file is not recorded")
+	}
+}
+
+func TestManifestKeptByConflictingAdd(t *testing.T) {
+	projectPath := generateOnDisk(t, &Config{ProjectName: "orders", Architecture: "basic", License: "MIT"})
+	before, err := ReadProjectManifest(projectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := DetectProject(projectPath, "")
+	if err != nil {
+		t.Fatalf("DetectProject failed: %v", err)
+	}
+	log := quietLogger(t)
+	writer := NewNoClobberWriter(NewDiskWriter())
+	adder, err := NewAdder(config, projectPath, log, writer)
+	if err != nil {
+		t.Fatalf("NewAdder failed: %v", err)
+	}
+
+	// The existing MIT LICENSE is kept
+	if err := adder.AddLicense("Apache"); err != nil {
+		t.Fatalf("AddLicense failed: %v", err)
+	}
+	if err := adder.AddDocker(); err != nil {
+		t.Fatalf("AddDocker failed: %v", err)
+	}
+	if len(writer.Conflicts()) != 1 {
+		t.Fatalf("got conflicts %+v, want LICENSE", writer.Conflicts())
+	}
+	if err := adder.UpdateManifest(); err != nil {
+		t.Fatalf("UpdateManifest failed: %v", err)
+	}
+
+	after, err := ReadProjectManifest(projectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(after, before) {
+		t.Errorf("manifest changed by an add with conflicts:\n%+v\nwant\n%+v", after, before)
+	}
+}
+
+func TestAddLicenseKeepsHeaders(t *testing.T) {
+	projectPath := generateOnDisk(t, &Config{ProjectName: "orders", Architecture: "basic", License: "None"})
+
+	config, err := DetectProject(projectPath, "")
+	if err != nil {
+		t.Fatalf("DetectProject failed: %v", err)
+	}
+
+	log := quietLogger(t)
+	adder, err := NewAdder(config, projectPath, log, NewNoClobberWriter(NewDiskWriter()))
+	if err != nil {
+		t.Fatalf("NewAdder failed: %v", err)
+	}
+	if err := adder.AddLicense("MIT"); err != nil {
+		t.Fatalf("AddLicense failed: %v", err)
+	}
+	if err := adder.UpdateManifest(); err != nil {
+		t.Fatalf("UpdateManifest failed: %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(projectPath, "LICENSE")); err != nil || !strings.HasPrefix(string(data), "MIT License") {
+		t.Errorf("LICENSE = %q, %v", data, err)
+	}
+
+	// The files rendered without headers still match the project
+	config, err = DetectProject(projectPath, "")
+	if err != nil {
+		t.Fatalf("DetectProject failed: %v", err)
+	}
+	changes, err := CompareProject(config, projectPath)
+	if err != nil {
+		t.Fatalf("CompareProject failed: %v", err)
+	}
+	for _, change := range changes {
+		if change.Status != FileUnchanged {
+			t.Errorf("%s: %s after adding a license", change.File.Path, change.Status)
+		}
+	}
+}
+
+func TestManifestRegenerateKeepingFiles(t *testing.T) {
+	config := &Config{ProjectName: "orders", Architecture: "basic", License: "MIT"}
+	projectPath := generateOnDisk(t, config)
+
+	before, err := ReadProjectManifest(projectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readme, _ := before.File("README.md")
+	if err := os.WriteFile(filepath.Join(projectPath, "README.md"), []byte("# mine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A file recorded by gomake generate, which project generation doesn't render
+	component := ManifestFile{Path: "internal/models/order.go", Source: "components/entity.go", Hash: checksum([]byte("package models\n"))}
+	before.Files = append(before.Files, component)
+	if err := before.Write(NewDiskWriter(), projectPath); err != nil {
+		t.Fatal(err)
+	}
+
+	log := quietLogger(t)
+	writer := NewNoClobberWriter(NewDiskWriter())
+	gen, err := NewWithWriter(config, log, writer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gen.Generate(); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if conflicts := writer.Conflicts(); len(conflicts) != 1 {
+		t.Errorf("conflicts = %+v, want only README.md", conflicts)
+	}
+	after, err := ReadProjectManifest(projectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file, _ := after.File("README.md"); file != readme {
+		t.Errorf("kept README.md recorded as %+v, want the original %+v", file, readme)
+	}
+	if file, ok := after.File(component.Path); !ok || file != component {
+		t.Errorf("component recorded as %+v, want %+v", file, component)
+	}
+}
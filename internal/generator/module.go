@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"net/url"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// modulePath returns the Go module path of the project, falling back to
+// the bare project name
+func (c *Config) modulePath() string {
+	if c.ModulePath != "" {
+		return c.ModulePath
+	}
+	return c.ProjectName
+}
+
+// DetectModulePath derives a module path from the git remote of targetDir.
+// A project created at <repo>/services/api with origin
+// git@github.com:acme/platform.git gets github.com/acme/platform/services/api.
+// It returns an empty string when targetDir is not in a git repository
+// with an origin remote.
+func DetectModulePath(targetDir, projectName string) string {
+	toplevel, err := gitOutput(targetDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return ""
+	}
+
+	remote, err := gitOutput(targetDir, "remote", "get-url", "origin")
+	if err != nil {
+		return ""
+	}
+
+	base := ModulePathFromRemote(remote)
+	if base == "" {
+		return ""
+	}
+
+	absTarget, err := filepath.Abs(filepath.Join(targetDir, projectName))
+	if err != nil {
+		return ""
+	}
+
+	// Resolve symlinks so the path compares equal to git's toplevel
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(absTarget)); err == nil {
+		absTarget = filepath.Join(resolved, projectName)
+	}
+
+	rel, err := filepath.Rel(toplevel, absTarget)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+
+	return path.Join(base, filepath.ToSlash(rel))
+}
+
+// ModulePathFromRemote converts a git remote URL into a module path, e.g.
+// https://github.com/acme/app.git and git@github.com:acme/app.git both
+// become github.com/acme/app
+func ModulePathFromRemote(remote string) string {
+	remote = strings.TrimSpace(remote)
+	remote = strings.TrimSuffix(remote, "/")
+	remote = strings.TrimSuffix(remote, ".git")
+
+	var host, repoPath string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil || u.Scheme == "file" {
+			return ""
+		}
+		host, repoPath = u.Hostname(), u.Path
+	} else {
+		// scp-like syntax: [user@]host:path
+		i := strings.Index(remote, ":")
+		if i < 0 {
+			return ""
+		}
+		host, repoPath = remote[:i], remote[i+1:]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+	}
+
+	repoPath = strings.Trim(repoPath, "/")
+	if host == "" || repoPath == "" {
+		return ""
+	}
+	return host + "/" + repoPath
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
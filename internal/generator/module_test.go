@@ -0,0 +1,36 @@
+package generator
+
+import "testing"
+
+func TestModulePathFromRemote(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{"https://github.com/acme/app.git", "github.com/acme/app"},
+		{"https://github.com/acme/app", "github.com/acme/app"},
+		{"git@github.com:acme/app.git", "github.com/acme/app"},
+		{"ssh://git@git.internal:2222/platform/app.git", "git.internal/platform/app"},
+		{"gitlab.com:group/sub/app.git", "gitlab.com/group/sub/app"},
+		{"file:///srv/git/app.git", ""},
+		{"/srv/git/app.git", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ModulePathFromRemote(tt.remote); got != tt.want {
+			t.Errorf("ModulePathFromRemote(%q) = %q, want %q", tt.remote, got, tt.want)
+		}
+	}
+}
+
+func TestModulePathDefaultsToProjectName(t *testing.T) {
+	config := &Config{ProjectName: "myapp"}
+	if got := NewTemplateData(config).ModuleName; got != "myapp" {
+		t.Errorf("ModuleName = %q, want %q", got, "myapp")
+	}
+
+	config.ModulePath = "github.com/acme/myapp"
+	if got := NewTemplateData(config).ModuleName; got != "github.com/acme/myapp" {
+		t.Errorf("ModuleName = %q, want %q", got, "github.com/acme/myapp")
+	}
+}
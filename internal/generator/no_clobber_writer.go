@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// WriteStatus tells what NoClobberWriter did with a file
+type WriteStatus string
+
+const (
+	WriteCreated   WriteStatus = "created"
+	WriteUnchanged WriteStatus = "unchanged"
+	WriteConflict  WriteStatus = "conflict"
+)
+
+// WriteResult is a file handled by NoClobberWriter
+type WriteResult struct {
+	Path   string
+	Status WriteStatus
+	Source string
+}
+
+// NoClobberWriter writes only files that don't exist yet. Existing files
+// are never overwritten; they are reported as unchanged when their content
+// matches and as conflicts otherwise.
+type NoClobberWriter struct {
+	writer  Writer
+	results []WriteResult
+}
+
+// NewNoClobberWriter creates a writer that writes new files through writer
+func NewNoClobberWriter(writer Writer) *NoClobberWriter {
+	return &NoClobberWriter{writer: writer}
+}
+
+// MkdirAll creates a directory through the underlying writer
+func (nw *NoClobberWriter) MkdirAll(path string) error {
+	return nw.writer.MkdirAll(path)
+}
+
+// WriteFile writes the file unless it already exists
+func (nw *NoClobberWriter) WriteFile(path string, data []byte, perm os.FileMode, source string) error {
+	existing, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		if err := nw.writer.WriteFile(path, data, perm, source); err != nil {
+			return err
+		}
+		nw.record(path, WriteCreated, source)
+	case err != nil:
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	case bytes.Equal(existing, data):
+		nw.record(path, WriteUnchanged, source)
+	default:
+		nw.record(path, WriteConflict, source)
+	}
+	return nil
+}
+
+func (nw *NoClobberWriter) record(path string, status WriteStatus, source string) {
+	nw.results = append(nw.results, WriteResult{Path: path, Status: status, Source: source})
+}
+
+// Results returns the files handled so far, sorted by path
+func (nw *NoClobberWriter) Results() []WriteResult {
+	results := append([]WriteResult(nil), nw.results...)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Path < results[j].Path
+	})
+	return results
+}
+
+// Conflicts returns the existing files that would have been changed
+func (nw *NoClobberWriter) Conflicts() []WriteResult {
+	var conflicts []WriteResult
+	for _, result := range nw.Results() {
+		if result.Status == WriteConflict {
+			conflicts = append(conflicts, result)
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,193 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// PlannedFile is a project file to render. Path is relative to the
+// project and may itself be a template, e.g. cmd/{{.ProjectName}}/main.go.
+type PlannedFile struct {
+	Path string
+	// Template names a template of the TemplateManager to render
+	Template string
+	// Text is rendered instead when Template is empty, e.g. the content
+	// of a custom template file
+	Text string
+	// Mode defaults to 0644
+	Mode os.FileMode
+	// Source names where the content came from; defaults to Template
+	Source string
+}
+
+// OutputFile is a rendered file passed through the post-processors
+type OutputFile struct {
+	Path   string
+	Data   []byte
+	Mode   os.FileMode
+	Source string
+}
+
+// PostProcessor transforms a rendered file before it is written
+type PostProcessor func(file *OutputFile) error
+
+// Pipeline renders planned files with a shared TemplateManager, runs every
+// file through the post-processors and writes it. It implements Writer so
+// files generated in Go code are post-processed the same way.
+type Pipeline struct {
+	templates  *TemplateManager
+	writer     Writer
+	processors []PostProcessor
+}
+
+// NewPipeline creates a pipeline writing through writer
+func NewPipeline(templates *TemplateManager, writer Writer, processors ...PostProcessor) *Pipeline {
+	return &Pipeline{
+		templates:  templates,
+		writer:     writer,
+		processors: processors,
+	}
+}
+
+// DefaultPostProcessors returns the post-processors applied to generated
+// projects: license headers, gofmt and executable scripts
+func DefaultPostProcessors(config *Config) []PostProcessor {
+	return []PostProcessor{
+		LicenseHeader(config.ProjectName, config.License, config.year()),
+		FormatGo,
+		ExecutableScripts,
+	}
+}
+
+// Templates returns the template manager shared by the pipeline
+func (p *Pipeline) Templates() *TemplateManager {
+	return p.templates
+}
+
+// Writer returns the writer the pipeline writes through
+func (p *Pipeline) Writer() Writer {
+	return p.writer
+}
+
+// Render renders files into projectPath in path order
+func (p *Pipeline) Render(projectPath string, files []PlannedFile, data *TemplateData) error {
+	planned := append([]PlannedFile(nil), files...)
+	sort.SliceStable(planned, func(i, j int) bool {
+		return planned[i].Path < planned[j].Path
+	})
+
+	for _, file := range planned {
+		if err := p.renderFile(projectPath, file, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Pipeline) renderFile(projectPath string, file PlannedFile, data *TemplateData) error {
+	path, err := p.templates.renderString(file.Path, file.Path, data)
+	if err != nil {
+		return fmt.Errorf("failed to render path %s: %w", file.Path, err)
+	}
+	path = filepath.FromSlash(path)
+
+	// Each file gets the package name of its own directory
+	fileData := *data
+	fileData.Package = filepath.Base(filepath.Dir(path))
+
+	var content string
+	if file.Template != "" {
+		content, err = p.templates.RenderTemplate(file.Template, &fileData)
+	} else {
+		content, err = p.templates.RenderText(file.Path, file.Text, &fileData)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render %s: %w", path, err)
+	}
+
+	mode := file.Mode
+	if mode == 0 {
+		mode = 0644
+	}
+	source := file.Source
+	if source == "" {
+		source = file.Template
+	}
+
+	return p.WriteFile(filepath.Join(projectPath, path), []byte(content), mode, source)
+}
+
+// MkdirAll creates a directory through the underlying writer
+func (p *Pipeline) MkdirAll(path string) error {
+	return p.writer.MkdirAll(path)
+}
+
+// WriteFile post-processes a file and writes it through the underlying
+// writer
+func (p *Pipeline) WriteFile(path string, data []byte, perm os.FileMode, source string) error {
+	file := &OutputFile{Path: path, Data: data, Mode: perm, Source: source}
+
+	for _, process := range p.processors {
+		if err := process(file); err != nil {
+			return err
+		}
+	}
+
+	if err := p.writer.WriteFile(file.Path, file.Data, file.Mode, file.Source); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", file.Path, err)
+	}
+	return nil
+}
+
+// FormatGo formats Go files with gofmt
+func FormatGo(file *OutputFile) error {
+	if filepath.Ext(file.Path) != ".go" {
+		return nil
+	}
+
+	formatted, err := format.Source(file.Data)
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", file.Path, err)
+	}
+	file.Data = formatted
+	return nil
+}
+
+// ExecutableScripts makes shell scripts and files starting with a shebang
+// executable
+func ExecutableScripts(file *OutputFile) error {
+	if filepath.Ext(file.Path) == ".sh" || bytes.HasPrefix(file.Data, []byte("#!")) {
+		file.Mode |= 0111
+	}
+	return nil
+}
+
+// spdxIdentifiers maps the supported licenses to SPDX identifiers
+var spdxIdentifiers = map[string]string{
+	"MIT":    "MIT",
+	"Apache": "Apache-2.0",
+	"BSD":    "BSD-3-Clause",
+	"GPL":    "GPL-3.0-or-later",
+}
+
+// LicenseHeader returns a post-processor adding an SPDX license header to
+// Go files. It does nothing without a license or when a file already has
+// a header.
+func LicenseHeader(projectName, license string, year int) PostProcessor {
+	return func(file *OutputFile) error {
+		id, ok := spdxIdentifiers[license]
+		if !ok || filepath.Ext(file.Path) != ".go" || bytes.Contains(file.Data, []byte("SPDX-License-Identifier")) {
+			return nil
+		}
+
+		header := fmt.Sprintf("// Copyright %d The %s Authors\n// SPDX-License-Identifier: %s\n\n",
+			year, projectName, id)
+		file.Data = append([]byte(header), file.Data...)
+		return nil
+	}
+}
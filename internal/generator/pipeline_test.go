@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"go/format"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPipelineRender(t *testing.T) {
+	templates, err := NewTemplateManagerWithLayers(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writer := NewMemoryWriter()
+	config := &Config{ProjectName: "shop", License: "MIT"}
+	pipeline := NewPipeline(templates, writer, DefaultPostProcessors(config)...)
+
+	files := []PlannedFile{
+		{Path: "cmd/{{.ProjectName}}/main.go", Text: "package {{.Package}}\nfunc main() {\nprintln( 1 )\n}\n"},
+		{Path: "scripts/run.sh", Text: "echo {{.ProjectName}}\n", Source: "test"},
+		{Path: "tool", Text: "#!/usr/bin/env bash\n"},
+		{Path: "internal/utils/utils.go", Template: "common/utils"},
+	}
+	if err := pipeline.Render("out", files, NewTemplateData(config)); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	main, ok := writer.File(filepath.Join("out", "cmd", "shop", "main.go"))
+	if !ok {
+		t.Fatal("templated path cmd/shop/main.go not rendered")
+	}
+	want := "// Copyright 2025 The shop Authors\n// SPDX-License-Identifier: MIT\n\npackage shop\n\nfunc main() {\n\tprintln(1)\n}\n"
+	if string(main.Data) != want {
+		t.Errorf("main.go = %q, want %q", main.Data, want)
+	}
+
+	for _, path := range []string{"scripts/run.sh", "tool"} {
+		file, ok := writer.File(filepath.Join("out", filepath.FromSlash(path)))
+		if !ok || file.Mode != 0755 {
+			t.Errorf("%s should be executable, got %+v", path, file)
+		}
+	}
+
+	utils, ok := writer.File(filepath.Join("out", "internal", "utils", "utils.go"))
+	if !ok || utils.Source != "common/utils" {
+		t.Fatalf("unexpected utils.go: %+v", utils)
+	}
+	if formatted, err := format.Source(utils.Data); err != nil || string(formatted) != string(utils.Data) {
+		t.Errorf("utils.go is not gofmt-clean: %v", err)
+	}
+}
+
+func TestPipelineRejectsInvalidGo(t *testing.T) {
+	templates, err := NewTemplateManagerWithLayers(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pipeline := NewPipeline(templates, NewMemoryWriter(), FormatGo)
+	err = pipeline.Render("out", []PlannedFile{{Path: "main.go", Text: "package main\nfunc {\n"}}, &TemplateData{})
+	if err == nil || !strings.Contains(err.Error(), "main.go") {
+		t.Fatalf("Render() error = %v, want a format error naming main.go", err)
+	}
+}
+
+func TestLicenseHeaderIsIdempotent(t *testing.T) {
+	header := LicenseHeader("shop", "Apache", 2025)
+
+	file := &OutputFile{Path: "main.go", Data: []byte("package main\n")}
+	for i := 0; i < 2; i++ {
+		if err := header(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if strings.Count(string(file.Data), "SPDX-License-Identifier: Apache-2.0") != 1 {
+		t.Errorf("unexpected header:\n%s", file.Data)
+	}
+
+	none := &OutputFile{Path: "main.go", Data: []byte("package main\n")}
+	if err := LicenseHeader("shop", "None", 2025)(none); err != nil || string(none.Data) != "package main\n" {
+		t.Errorf("license None should not add a header, got %q", none.Data)
+	}
+}
@@ -0,0 +1,173 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DetectProject reads the configuration of an existing project in dir.
+// Projects with a manifest are configured from it. Otherwise the module
+// path is read from go.mod, the project name from cmd/<name>, the license
+// from LICENSE and the architecture is detected from the directory layout.
+// A non-empty architecture overrides the recorded or detected one.
+func DetectProject(dir, architecture string) (*Config, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+
+	manifest, err := ReadProjectManifest(absDir)
+	if err == nil {
+		config := manifest.Config(filepath.Dir(absDir))
+		if architecture != "" {
+			config.Architecture = architecture
+		}
+		if _, ok := LookupArchitecture(config.Architecture); !ok {
+			return nil, fmt.Errorf("unsupported architecture: %s", config.Architecture)
+		}
+		return config, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	module, err := ReadModulePath(filepath.Join(absDir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	if architecture == "" {
+		architecture, err = DetectArchitecture(absDir)
+		if err != nil {
+			return nil, err
+		}
+	} else if _, ok := LookupArchitecture(architecture); !ok {
+		return nil, fmt.Errorf("unsupported architecture: %s", architecture)
+	}
+
+	return &Config{
+		ProjectName:  detectProjectName(absDir, module),
+		ModulePath:   module,
+		Architecture: architecture,
+		TargetDir:    filepath.Dir(absDir),
+		License:      detectLicense(absDir),
+		AutoYes:      true,
+		Variables:    make(map[string]string),
+	}, nil
+}
+
+// ReadModulePath returns the module path declared in a go.mod file
+func ReadModulePath(goModPath string) (string, error) {
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("no go.mod found in %s; run gomake inside a Go project", filepath.Dir(goModPath))
+		}
+		return "", fmt.Errorf("failed to read go.mod: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		module, ok := strings.CutPrefix(line, "module")
+		if !ok || module == "" || (module[0] != ' ' && module[0] != '\t') {
+			continue
+		}
+
+		module = strings.TrimSpace(module)
+		if unquoted, err := strconv.Unquote(module); err == nil {
+			module = unquoted
+		}
+		if module != "" {
+			return module, nil
+		}
+	}
+
+	return "", fmt.Errorf("no module directive in %s", goModPath)
+}
+
+// DetectArchitecture guesses the architecture of the project in dir from
+// the share of each architecture's directories that exist. At least half
+// of them must exist; ties go to the architecture registered first.
+func DetectArchitecture(dir string) (string, error) {
+	best, bestScore := "", 0.0
+
+	for _, spec := range Architectures() {
+		dirs := spec.New().GetStructure().Directories
+		if len(dirs) == 0 {
+			continue
+		}
+
+		found := 0
+		for _, d := range dirs {
+			// Templated directories can't be checked without the data
+			if strings.Contains(d, "{{") {
+				continue
+			}
+			if info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(d))); err == nil && info.IsDir() {
+				found++
+			}
+		}
+
+		score := float64(found) / float64(len(dirs))
+		if score > bestScore {
+			best, bestScore = spec.Name, score
+		}
+	}
+
+	if bestScore < 0.5 {
+		return "", fmt.Errorf("could not detect the architecture of %s; pass --arch", dir)
+	}
+	return best, nil
+}
+
+// detectProjectName returns the name of the only directory under cmd/,
+// falling back to the last element of the module path
+func detectProjectName(dir, module string) string {
+	entries, err := os.ReadDir(filepath.Join(dir, "cmd"))
+	if err == nil {
+		var names []string
+		for _, entry := range entries {
+			if entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+		if len(names) == 1 {
+			return names[0]
+		}
+	}
+	return path.Base(module)
+}
+
+// licenseTitles maps the first line of generated LICENSE files to the
+// license names
+var licenseTitles = map[string]string{
+	"MIT License":                "MIT",
+	"Apache License":             "Apache",
+	"BSD 3-Clause License":       "BSD",
+	"GNU GENERAL PUBLIC LICENSE": "GPL",
+}
+
+// detectLicense returns the license of the LICENSE file in dir, or None
+func detectLicense(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "LICENSE"))
+	if err != nil {
+		return "None"
+	}
+
+	title, _, _ := strings.Cut(string(data), "\n")
+	if license, ok := licenseTitles[strings.TrimSpace(title)]; ok {
+		return license
+	}
+	return "None"
+}
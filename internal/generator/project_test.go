@@ -0,0 +1,223 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectProject(t *testing.T) {
+	for _, arch := range goldenArchitectures {
+		t.Run(arch, func(t *testing.T) {
+			projectPath := generateOnDisk(t, &Config{
+				ProjectName:  "orders",
+				ModulePath:   "github.com/acme/orders-svc",
+				Architecture: arch,
+				License:      "BSD",
+			})
+
+			config, err := DetectProject(projectPath, "")
+			if err != nil {
+				t.Fatalf("DetectProject failed: %v", err)
+			}
+			if config.Architecture != arch {
+				t.Errorf("Architecture = %s, want %s", config.Architecture, arch)
+			}
+			if config.ModulePath != "github.com/acme/orders-svc" {
+				t.Errorf("ModulePath = %s", config.ModulePath)
+			}
+			if config.ProjectName != "orders" {
+				t.Errorf("ProjectName = %s, want orders", config.ProjectName)
+			}
+			if config.License != "BSD" {
+				t.Errorf("License = %s, want BSD", config.License)
+			}
+		})
+	}
+}
+
+func TestDetectProjectWithoutLayout(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/x // comment\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DetectProject(dir, ""); err == nil {
+		t.Fatal("expected error for an unrecognized layout")
+	}
+
+	config, err := DetectProject(dir, "mvc")
+	if err != nil {
+		t.Fatalf("DetectProject with --arch failed: %v", err)
+	}
+	if config.ModulePath != "example.com/x" || config.ProjectName != "x" || config.License != "None" {
+		t.Errorf("unexpected config: %+v", config)
+	}
+}
+
+func TestAdderNeverOverwrites(t *testing.T) {
+	projectPath := generateOnDisk(t, &Config{
+		ProjectName:  "orders",
+		Architecture: "basic",
+		License:      "MIT",
+	})
+
+	dockerfile := filepath.Join(projectPath, "Dockerfile")
+	if err := os.WriteFile(dockerfile, []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := DetectProject(projectPath, "")
+	if err != nil {
+		t.Fatalf("DetectProject failed: %v", err)
+	}
+
+	log := quietLogger(t)
+	writer := NewNoClobberWriter(NewDiskWriter())
+	adder, err := NewAdder(config, projectPath, log, writer)
+	if err != nil {
+		t.Fatalf("NewAdder failed: %v", err)
+	}
+
+	if err := adder.AddDocker(); err != nil {
+		t.Fatalf("AddDocker failed: %v", err)
+	}
+	if err := adder.AddLicense("MIT"); err != nil {
+		t.Fatalf("AddLicense failed: %v", err)
+	}
+	added, err := adder.AddFeature("jwt")
+	if err != nil {
+		t.Fatalf("AddFeature failed: %v", err)
+	}
+	if len(added) != 1 || added[0].Name != "jwt" {
+		t.Errorf("AddFeature added %v", featureNames(added))
+	}
+
+	want := map[string]WriteStatus{
+		".dockerignore":      WriteCreated,
+		"Dockerfile":         WriteConflict,
+		"LICENSE":            WriteUnchanged,
+		"docker-compose.yml": WriteCreated,
+		"pkg/auth/jwt.go":    WriteCreated,
+	}
+	results := writer.Results()
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for _, result := range results {
+		rel, _ := filepath.Rel(projectPath, result.Path)
+		if status := want[filepath.ToSlash(rel)]; status != result.Status {
+			t.Errorf("%s: status %s, want %s", rel, result.Status, status)
+		}
+	}
+
+	data, err := os.ReadFile(dockerfile)
+	if err != nil || string(data) != "FROM scratch\n" {
+		t.Errorf("Dockerfile was overwritten: %q", data)
+	}
+}
+
+func TestCompareProject(t *testing.T) {
+	generated := generateOnDisk(t, &Config{
+		ProjectName:  "orders",
+		Architecture: "clean",
+		License:      "MIT",
+		Features:     []string{"redis"},
+	})
+
+	// CI checkouts rarely use the project name as directory
+	projectPath := filepath.Join(filepath.Dir(generated), "checkout")
+	if err := os.Rename(generated, projectPath); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := DetectProject(projectPath, "")
+	if err != nil {
+		t.Fatalf("DetectProject failed: %v", err)
+	}
+
+	changes, err := CompareProject(config, projectPath)
+	if err != nil {
+		t.Fatalf("CompareProject failed: %v", err)
+	}
+	if len(changes) == 0 {
+		t.Fatal("CompareProject compared no files")
+	}
+	for _, change := range changes {
+		if change.Status != FileUnchanged {
+			t.Errorf("%s: %s right after generation", change.File.Path, change.Status)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(projectPath, "README.md"), []byte("# orders\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(projectPath, "pkg", "cache", "redis.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err = CompareProject(config, projectPath)
+	if err != nil {
+		t.Fatalf("CompareProject failed: %v", err)
+	}
+	want := map[string]ChangeStatus{
+		"README.md":          FileModified,
+		"pkg/cache/redis.go": FileAdded,
+	}
+	for _, change := range changes {
+		rel, _ := filepath.Rel(projectPath, change.File.Path)
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(rel, ".gomake/") {
+			t.Errorf("%s is compared", rel)
+		}
+		if status, ok := want[rel]; ok && change.Status != status || !ok && change.Status != FileUnchanged {
+			t.Errorf("%s: status %s", rel, change.Status)
+		}
+	}
+}
+
+func TestCompareProjectTemplateOverrides(t *testing.T) {
+	projectPath := generateOnDisk(t, &Config{ProjectName: "orders", Architecture: "basic", License: "MIT"})
+	config, err := DetectProject(projectPath, "")
+	if err != nil {
+		t.Fatalf("DetectProject failed: %v", err)
+	}
+
+	statusOf := func(path string) ChangeStatus {
+		t.Helper()
+		changes, err := CompareProject(config, projectPath)
+		if err != nil {
+			t.Fatalf("CompareProject failed: %v", err)
+		}
+		for _, change := range changes {
+			if change.File.Path == filepath.Join(projectPath, path) {
+				return change.Status
+			}
+		}
+		t.Fatalf("%s was not compared", path)
+		return ""
+	}
+
+	// Overrides in the working directory belong to another project
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	elsewhere := t.TempDir()
+	writeTestFile(t, filepath.Join(elsewhere, ".gomake", "templates", "common", "env.tmpl"), "ELSEWHERE=1\n", 0644)
+	if err := os.Chdir(elsewhere); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	if status := statusOf(".env"); status != FileUnchanged {
+		t.Errorf(".env: status %s with overrides of the working directory", status)
+	}
+
+	// The project's own overrides are rendered
+	writeTestFile(t, filepath.Join(projectPath, ".gomake", "templates", "common", "env.tmpl"), "PROJECT=1\n", 0644)
+	if status := statusOf(".env"); status != FileModified {
+		t.Errorf(".env: status %s, want the project override compared", status)
+	}
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"fmt"
+)
+
+// ArchitectureSpec describes an architecture available for generation
+type ArchitectureSpec struct {
+	Name        string
+	Description string
+	// New creates the architecture, which provides its structure and
+	// generates its files
+	New func() Architecture
+	// Data optionally builds architecture specific template data
+	Data func(config *Config) interface{}
+	// Prompts lists template variables to ask the user for
+	Prompts []Prompt
+	// Components optionally tells gomake generate where the pieces of an
+	// entity go
+	Components *ComponentLayout
+	// Source is where the architecture was loaded from; empty for
+	// built-in architectures
+	Source string
+}
+
+var (
+	architectures     = make(map[string]*ArchitectureSpec)
+	architectureOrder []string
+)
+
+// RegisterArchitecture makes an architecture available by name. It panics
+// if the name is empty or already registered.
+func RegisterArchitecture(spec ArchitectureSpec) {
+	if spec.Name == "" || spec.New == nil {
+		panic("generator: architecture must have a name and constructor")
+	}
+	if _, exists := architectures[spec.Name]; exists {
+		panic(fmt.Sprintf("generator: architecture %s registered twice", spec.Name))
+	}
+
+	architectures[spec.Name] = &spec
+	architectureOrder = append(architectureOrder, spec.Name)
+}
+
+// LookupArchitecture returns the registered architecture with the given name
+func LookupArchitecture(name string) (*ArchitectureSpec, bool) {
+	spec, ok := architectures[name]
+	return spec, ok
+}
+
+// Architectures returns all registered architectures in registration order
+func Architectures() []*ArchitectureSpec {
+	specs := make([]*ArchitectureSpec, 0, len(architectureOrder))
+	for _, name := range architectureOrder {
+		specs = append(specs, architectures[name])
+	}
+	return specs
+}
+
+// ArchitectureNames returns the names of all registered architectures
+func ArchitectureNames() []string {
+	return append([]string(nil), architectureOrder...)
+}
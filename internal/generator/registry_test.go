@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuiltinArchitecturesRegistered(t *testing.T) {
+	want := []string{"hexagonal", "clean", "mvc", "basic"}
+	if got := ArchitectureNames(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ArchitectureNames() = %v, want %v", got, want)
+	}
+
+	for _, name := range want {
+		spec, ok := LookupArchitecture(name)
+		if !ok {
+			t.Fatalf("architecture %s not registered", name)
+		}
+		if spec.Description == "" {
+			t.Errorf("architecture %s has no description", name)
+		}
+		if arch := spec.New(); arch.GetName() != name {
+			t.Errorf("architecture %s constructs %s", name, arch.GetName())
+		}
+	}
+}
+
+func TestRegisterArchitectureRejectsDuplicates(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for duplicate registration")
+		}
+	}()
+
+	RegisterArchitecture(ArchitectureSpec{
+		Name: "basic",
+		New:  func() Architecture { return NewBasicArchitecture() },
+	})
+}
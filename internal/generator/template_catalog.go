@@ -0,0 +1,303 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateKind tells where a template comes from
+type TemplateKind string
+
+const (
+	TemplateKindEmbedded TemplateKind = "embedded"
+	TemplateKindConfig   TemplateKind = "config"
+	TemplateKindDisk     TemplateKind = "disk"
+)
+
+// TemplateEntry is a single template known to gomake
+type TemplateEntry struct {
+	Name   string
+	Kind   TemplateKind
+	Source string
+	Text   string
+
+	// Layer is the template layer that won for embedded templates
+	Layer string
+}
+
+// ListTemplateEntries returns the embedded templates, as overridden in the
+// current directory like for a project generated there, the files of custom templates from
+// config and the templates of directory-based architectures
+func ListTemplateEntries(configFile *ConfigFile) ([]TemplateEntry, error) {
+	tm, err := NewTemplateManager(".")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []TemplateEntry
+	for _, name := range tm.ListTemplates() {
+		text, err := tm.RawTemplate(name)
+		if err != nil {
+			return nil, err
+		}
+		origin, err := tm.Origin(name)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, TemplateEntry{
+			Name:   name,
+			Kind:   TemplateKindEmbedded,
+			Source: origin.Source,
+			Text:   text,
+			Layer:  origin.Layer,
+		})
+	}
+
+	if configFile != nil {
+		for _, tc := range configFile.Templates {
+			paths := make([]string, 0, len(tc.Files))
+			for path := range tc.Files {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+
+			for _, path := range paths {
+				entries = append(entries, TemplateEntry{
+					Name:   tc.Name + "/" + path,
+					Kind:   TemplateKindConfig,
+					Source: tc.Source,
+					Text:   tc.Files[path],
+				})
+			}
+		}
+	}
+
+	for _, spec := range Architectures() {
+		arch, ok := spec.New().(*DirArchitecture)
+		if !ok {
+			continue
+		}
+
+		templates, err := arch.templateFiles()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rel := range templates {
+			source := filepath.Join(arch.dir, rel)
+			text, err := os.ReadFile(source)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read template %s: %w", source, err)
+			}
+			entries = append(entries, TemplateEntry{
+				Name:   arch.name + "/" + strings.TrimSuffix(filepath.ToSlash(rel), ".tmpl"),
+				Kind:   TemplateKindDisk,
+				Source: source,
+				Text:   string(text),
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// FindTemplateEntry returns the template with the given name
+func FindTemplateEntry(configFile *ConfigFile, name string) (*TemplateEntry, error) {
+	entries, err := ListTemplateEntries(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		if entries[i].Name == name {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("template %s not found (see gomake template list)", name)
+}
+
+// Render renders the template with data, resolving partials and bases
+// against the same layered template set as ListTemplateEntries
+func (e *TemplateEntry) Render(data *TemplateData) (string, error) {
+	tm, err := NewTemplateManager(".")
+	if err != nil {
+		return "", err
+	}
+
+	if e.Kind == TemplateKindEmbedded {
+		return tm.RenderTemplate(e.Name, data)
+	}
+	return tm.RenderText(e.Name, e.Text, data)
+}
+
+// NewPreviewData builds template data for rendering a single template
+// outside a project. Values named after TemplateData fields (ProjectName,
+// ModuleName, Architecture, License, Package, WithDocker, WithMakefile,
+// WithGit) set those fields; all others become Variables.
+func NewPreviewData(templateName string, values map[string]string) (*TemplateData, error) {
+	config := &Config{
+		ProjectName:  "myapp",
+		Architecture: "basic",
+		License:      "MIT",
+		Variables:    make(map[string]string),
+	}
+
+	// Templates of an architecture get its architecture data
+	if i := strings.Index(templateName, "/"); i > 0 {
+		if _, ok := LookupArchitecture(templateName[:i]); ok {
+			config.Architecture = templateName[:i]
+		}
+	}
+
+	pkg := filepath.Base(filepath.Dir(templateName))
+	for key, value := range values {
+		var err error
+		switch key {
+		case "ProjectName":
+			config.ProjectName = value
+		case "ModuleName", "ModulePath":
+			config.ModulePath = value
+		case "Architecture":
+			config.Architecture = value
+		case "License":
+			config.License = value
+		case "Package":
+			pkg = value
+		case "WithDocker":
+			config.WithDocker, err = strconv.ParseBool(value)
+		case "WithMakefile":
+			config.WithMakefile, err = strconv.ParseBool(value)
+		case "WithGit":
+			config.WithGit, err = strconv.ParseBool(value)
+		default:
+			config.Variables[key] = value
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %s", key, value)
+		}
+	}
+
+	data := NewTemplateData(config)
+	data.Package = pkg
+	return data, nil
+}
+
+// TemplateError is a problem found in a template file
+type TemplateError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (e TemplateError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Message)
+}
+
+// parseTemplate parses text with the template function library, for
+// checking a template without rendering it
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs(nil)).Parse(text)
+}
+
+// ValidateTemplateDir parses every .tmpl file under dir, including its
+// templated path, and the architecture manifest if there is one. It
+// returns the problems found; the error is only set if dir can't be read.
+func ValidateTemplateDir(dir string) ([]TemplateError, error) {
+	var problems []TemplateError
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		if entry.Name() == ArchitectureManifestFile {
+			if problem := validateManifest(path); problem != nil {
+				problems = append(problems, *problem)
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(path, ".tmpl") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if _, err := parseTemplate(rel, filepath.ToSlash(rel)); err != nil {
+			problem := templateError(path, rel, err)
+			problem.Line = 0
+			problem.Message = "invalid path template: " + problem.Message
+			problems = append(problems, problem)
+		}
+
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read template %s: %w", path, err)
+		}
+		if _, err := parseTemplate(path, string(text)); err != nil {
+			problems = append(problems, templateError(path, path, err))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates in %s: %w", dir, err)
+	}
+
+	return problems, nil
+}
+
+func validateManifest(path string) *TemplateError {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &TemplateError{File: path, Message: err.Error()}
+	}
+
+	var manifest ArchitectureManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		// yaml.v3 reports "yaml: line N: message"
+		message := strings.TrimPrefix(err.Error(), "yaml: ")
+		line := 0
+		if rest, ok := strings.CutPrefix(message, "line "); ok {
+			if i := strings.Index(rest, ": "); i > 0 {
+				if n, convErr := strconv.Atoi(rest[:i]); convErr == nil {
+					line, message = n, rest[i+2:]
+				}
+			}
+		}
+		return &TemplateError{File: path, Line: line, Message: message}
+	}
+	return nil
+}
+
+// templateError converts a parse error of the template named name into a
+// TemplateError. text/template reports "template: name:N: message".
+func templateError(file, name string, err error) TemplateError {
+	message, ok := strings.CutPrefix(err.Error(), "template: "+name+":")
+	if !ok {
+		return TemplateError{File: file, Message: err.Error()}
+	}
+
+	line := 0
+	if i := strings.Index(message, ": "); i > 0 {
+		if n, convErr := strconv.Atoi(message[:i]); convErr == nil {
+			line, message = n, message[i+2:]
+		}
+	}
+	return TemplateError{File: file, Line: line, Message: message}
+}
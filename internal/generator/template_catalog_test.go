@@ -0,0 +1,154 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindTemplateEntry(t *testing.T) {
+	configFile := &ConfigFile{Templates: []TemplateConfig{{
+		Name:   "svc",
+		Source: ".gomake.yml",
+		Files:  map[string]string{"cmd/main.go": "package main // {{.ProjectName}}\n"},
+	}}}
+
+	entry, err := FindTemplateEntry(configFile, "hexagonal/main.go")
+	if err != nil {
+		t.Fatalf("FindTemplateEntry() error = %v", err)
+	}
+	if entry.Kind != TemplateKindEmbedded || !strings.Contains(entry.Text, "{{") {
+		t.Errorf("unexpected embedded entry: %+v", entry)
+	}
+
+	entry, err = FindTemplateEntry(configFile, "svc/cmd/main.go")
+	if err != nil {
+		t.Fatalf("FindTemplateEntry() error = %v", err)
+	}
+	data, err := NewPreviewData(entry.Name, map[string]string{"ProjectName": "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := entry.Render(data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if content != "package main // x\n" {
+		t.Errorf("Render() = %q", content)
+	}
+
+	if _, err := FindTemplateEntry(configFile, "missing"); err == nil {
+		t.Error("FindTemplateEntry() of a missing template succeeded")
+	}
+}
+
+func TestValidateTemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, ArchitectureManifestFile), "name: broken\nprompts: [\n", 0644)
+	writeTestFile(t, filepath.Join(dir, "ok.go.tmpl"), "package {{.Package}}\n", 0644)
+	writeTestFile(t, filepath.Join(dir, "bad.go.tmpl"), "package main\n\n{{ if }}\n", 0644)
+	writeTestFile(t, filepath.Join(dir, "{{.Name", "x.tmpl"), "x\n", 0644)
+
+	problems, err := ValidateTemplateDir(dir)
+	if err != nil {
+		t.Fatalf("ValidateTemplateDir() error = %v", err)
+	}
+
+	var got []string
+	for _, problem := range problems {
+		rel, _ := filepath.Rel(dir, problem.File)
+		got = append(got, fmt.Sprintf("%s:%d", filepath.ToSlash(rel), problem.Line))
+	}
+	want := []string{"bad.go.tmpl:3", "manifest.yml:2", "{{.Name/x.tmpl:0"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("problems = %v, want %v (%v)", got, want, problems)
+	}
+}
+
+func TestTemplateLayers(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	user := filepath.Join(root, "user")
+
+	writeTestFile(t, filepath.Join(user, "common", "logger.tmpl"), "package {{.Package}} // user\n", 0644)
+	writeTestFile(t, filepath.Join(user, "common", "utils.tmpl"), "package {{.Package}} // user\n", 0644)
+	writeTestFile(t, filepath.Join(project, "common", "utils.tmpl"), "package {{.Package}} // project\n", 0644)
+
+	tm, err := NewTemplateManagerWithLayers([]TemplateLayer{
+		{Name: "project", Dir: project},
+		{Name: "user", Dir: user},
+		{Name: "missing", Dir: filepath.Join(root, "missing")},
+	})
+	if err != nil {
+		t.Fatalf("NewTemplateManagerWithLayers() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		layer string
+		text  string
+	}{
+		{"common/logger", "user", "package logger // user\n"},
+		{"common/utils", "project", "package utils // project\n"},
+		{"common/config", EmbeddedLayer, ""},
+	}
+
+	for _, tt := range tests {
+		origin, err := tm.Origin(tt.name)
+		if err != nil {
+			t.Fatalf("Origin(%s) error = %v", tt.name, err)
+		}
+		if origin.Layer != tt.layer {
+			t.Errorf("Origin(%s).Layer = %s, want %s", tt.name, origin.Layer, tt.layer)
+		}
+		if tt.text == "" {
+			continue
+		}
+
+		got, err := tm.RenderTemplate(tt.name, &TemplateData{Package: filepath.Base(tt.name)})
+		if err != nil {
+			t.Fatalf("RenderTemplate(%s) error = %v", tt.name, err)
+		}
+		if got != tt.text {
+			t.Errorf("RenderTemplate(%s) = %q, want %q", tt.name, got, tt.text)
+		}
+	}
+}
+
+func TestProjectTemplateOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, ".gomake", "templates", "common", "env.tmpl"), "OVERRIDDEN=1\n", 0644)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	log := quietLogger(t)
+	gen, err := New(&Config{ProjectName: "orders", Architecture: "basic", License: "MIT", TargetDir: "."}, log)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+	if err := gen.Generate(); err != nil {
+		t.Fatalf("failed to generate project: %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "orders", ".env")); err != nil || string(data) != "OVERRIDDEN=1\n" {
+		t.Errorf(".env = %q, %v, want the override", data, err)
+	}
+
+	// The template commands read the same overrides
+	entry, err := FindTemplateEntry(nil, "common/env")
+	if err != nil {
+		t.Fatalf("FindTemplateEntry() error = %v", err)
+	}
+	if entry.Layer != "project" {
+		t.Errorf("common/env comes from layer %s, want project", entry.Layer)
+	}
+}
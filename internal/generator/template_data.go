@@ -2,8 +2,6 @@ package generator
 
 import (
 	"fmt"
-	"strings"
-	"time"
 )
 
 // TemplateData contains data passed to templates
@@ -18,13 +16,30 @@ type TemplateData struct {
 	ModuleName      string
 	MainPackagePath string
 
+	// Package is the Go package name of the file being rendered,
+	// derived from its directory
+	Package string
+
 	// Configuration
 	WithDocker   bool
 	WithMakefile bool
 	WithGit      bool
 
+	// Features lists the enabled project features (docker, makefile,
+	// git and feature modules), see the hasFeature template function
+	Features []string
+
+	// Env holds the .env sections contributed by feature modules
+	Env []EnvSection
+
 	// Architecture specific data
 	ArchData interface{}
+
+	// Variables from the custom template, if any
+	Variables map[string]string
+
+	// Component is the entity scaffolded by gomake generate, if any
+	Component *Component
 }
 
 // NewTemplateData creates template data from config
@@ -33,27 +48,44 @@ func NewTemplateData(config *Config) *TemplateData {
 		ProjectName:  config.ProjectName,
 		Architecture: config.Architecture,
 		License:      config.License,
-		Year:         time.Now().Year(),
+		Year:         config.year(),
 		WithDocker:   config.WithDocker,
 		WithMakefile: config.WithMakefile,
 		WithGit:      config.WithGit,
+		Variables:    make(map[string]string),
+	}
+
+	for name, value := range config.Variables {
+		data.Variables[name] = value
 	}
 
 	// Computed fields
-	data.ProjectTitle = strings.Title(config.ProjectName)
-	data.ModuleName = config.ProjectName
+	data.ProjectTitle = titleCase(config.ProjectName)
+	data.ModuleName = config.modulePath()
 	data.MainPackagePath = fmt.Sprintf("cmd/%s", config.ProjectName)
 
+	if config.WithDocker {
+		data.Features = append(data.Features, "docker")
+	}
+	if config.WithMakefile {
+		data.Features = append(data.Features, "makefile")
+	}
+	if config.WithGit {
+		data.Features = append(data.Features, "git")
+	}
+
+	// Unknown features are rejected when the generator is created
+	modules, _ := ResolveFeatures(config.Features)
+	for _, module := range modules {
+		data.Features = append(data.Features, module.Name)
+		if module.Env != nil {
+			data.Env = append(data.Env, EnvSection{Feature: module.Name, Vars: module.Env(config)})
+		}
+	}
+
 	// Set architecture specific data
-	switch config.Architecture {
-	case "hexagonal":
-		data.ArchData = NewHexagonalData(config)
-	case "clean":
-		data.ArchData = NewCleanData(config)
-	case "mvc":
-		data.ArchData = NewMVCData(config)
-	case "basic":
-		data.ArchData = NewBasicData(config)
+	if spec, ok := LookupArchitecture(config.Architecture); ok && spec.Data != nil {
+		data.ArchData = spec.Data(config)
 	}
 
 	return data
@@ -0,0 +1,298 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateSource is a git repository of architectures pinned in the lockfile
+type TemplateSource struct {
+	Name   string `yaml:"name"`
+	URL    string `yaml:"url"`
+	Ref    string `yaml:"ref,omitempty"`
+	Commit string `yaml:"commit"`
+}
+
+// TemplateLock is the lockfile recording the pinned template sources
+type TemplateLock struct {
+	Sources []TemplateSource `yaml:"sources"`
+}
+
+// SourceManager fetches template sources into a local cache and pins them
+// in a lockfile
+type SourceManager struct {
+	cacheDir string
+	lockPath string
+}
+
+var sourceNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._\-]*$`)
+
+// NewSourceManager creates a source manager using the given cache
+// directory and lockfile
+func NewSourceManager(cacheDir, lockPath string) *SourceManager {
+	return &SourceManager{
+		cacheDir: cacheDir,
+		lockPath: lockPath,
+	}
+}
+
+// DefaultSourceManager returns the source manager for the user cache
+// (~/.cache/gomake/templates) and lockfile (~/.config/gomake/templates.lock)
+func DefaultSourceManager() *SourceManager {
+	home := os.Getenv("HOME")
+	return NewSourceManager(
+		filepath.Join(home, ".cache", "gomake", "templates"),
+		filepath.Join(home, ".config", "gomake", "templates.lock"),
+	)
+}
+
+// ParseSourceSpec splits "url@ref" into its URL and optional ref. The
+// user part of scp-like URLs (git@host:path) is not mistaken for a ref.
+func ParseSourceSpec(spec string) (url, ref string) {
+	pathStart := 0
+	if i := strings.Index(spec, "://"); i >= 0 {
+		if slash := strings.Index(spec[i+3:], "/"); slash >= 0 {
+			pathStart = i + 3 + slash
+		}
+	} else if colon := strings.Index(spec, ":"); colon >= 0 && !strings.Contains(spec[:colon], "/") {
+		pathStart = colon
+	}
+
+	if at := strings.LastIndex(spec, "@"); at > pathStart {
+		return spec[:at], spec[at+1:]
+	}
+	return spec, ""
+}
+
+// Add clones the source into the cache, checks out ref and pins the
+// resulting commit in the lockfile. An existing source with the same name
+// is only replaced when force is set.
+func (sm *SourceManager) Add(name, url, ref string, force bool) (*TemplateSource, error) {
+	if !sourceNameRegex.MatchString(name) {
+		return nil, fmt.Errorf("invalid template source name: %s", name)
+	}
+
+	lock, err := sm.readLock()
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	for i, source := range lock.Sources {
+		if source.Name == name {
+			index = i
+		}
+	}
+	if index >= 0 && !force {
+		return nil, fmt.Errorf("template source %s already exists (use --force to replace it)", name)
+	}
+
+	source := TemplateSource{Name: name, URL: url, Ref: ref}
+	if err := sm.fetch(&source); err != nil {
+		return nil, err
+	}
+
+	if index >= 0 {
+		lock.Sources[index] = source
+	} else {
+		lock.Sources = append(lock.Sources, source)
+	}
+
+	if err := sm.writeLock(lock); err != nil {
+		return nil, err
+	}
+
+	return &source, nil
+}
+
+// Sources returns the sources pinned in the lockfile
+func (sm *SourceManager) Sources() ([]TemplateSource, error) {
+	lock, err := sm.readLock()
+	if err != nil {
+		return nil, err
+	}
+	return lock.Sources, nil
+}
+
+// LoadArchitectures registers the architectures of every pinned source as
+// <source>/<architecture>. Nothing is fetched: sources missing from the
+// cache are skipped and reported in the error, see Restore.
+func (sm *SourceManager) LoadArchitectures() error {
+	sources, err := sm.Sources()
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, source := range sources {
+		dir := sm.sourceDir(source.Name)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			missing = append(missing, source.Name)
+			continue
+		}
+		if head, _ := gitOutput(dir, "rev-parse", "HEAD"); head != source.Commit {
+			if err := runGit(dir, "checkout", "--quiet", source.Commit); err != nil {
+				return fmt.Errorf("failed to check out pinned commit of %s: %w", source.Name, err)
+			}
+		}
+
+		if err := LoadArchitectureDir(architecturesRoot(dir), source.Name+"/"); err != nil {
+			return err
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("template source(s) %s not in the cache; fetch them with gomake template restore", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Restore fetches every pinned source missing from the cache at its
+// pinned commit
+func (sm *SourceManager) Restore() ([]TemplateSource, error) {
+	sources, err := sm.Sources()
+	if err != nil {
+		return nil, err
+	}
+
+	var restored []TemplateSource
+	for _, source := range sources {
+		if _, err := os.Stat(sm.sourceDir(source.Name)); !os.IsNotExist(err) {
+			continue
+		}
+
+		pinned := source
+		pinned.Ref = source.Commit
+		if err := sm.fetch(&pinned); err != nil {
+			return restored, err
+		}
+		restored = append(restored, source)
+	}
+	return restored, nil
+}
+
+// fetch clones source into the cache, checks out its ref and records the
+// resolved commit
+func (sm *SourceManager) fetch(source *TemplateSource) error {
+	if err := os.MkdirAll(sm.cacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create template cache: %w", err)
+	}
+
+	// Clone next to the final location so a failed fetch leaves the
+	// cache untouched
+	tempDir, err := os.MkdirTemp(sm.cacheDir, "."+source.Name+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create template cache: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cloneDir := filepath.Join(tempDir, "repo")
+	if err := runGit("", "clone", "--quiet", "--", source.URL, cloneDir); err != nil {
+		return fmt.Errorf("failed to clone %s: %w", source.URL, err)
+	}
+
+	if source.Ref != "" {
+		if err := runGit(cloneDir, "checkout", "--quiet", source.Ref); err != nil {
+			return fmt.Errorf("failed to check out %s: %w", source.Ref, err)
+		}
+	}
+
+	commit, err := gitOutput(cloneDir, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to resolve commit of %s: %w", source.URL, err)
+	}
+	source.Commit = commit
+
+	if !hasArchitectures(architecturesRoot(cloneDir)) {
+		return fmt.Errorf("%s contains no architectures (directories with %s)", source.URL, ArchitectureManifestFile)
+	}
+
+	dir := sm.sourceDir(source.Name)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to replace cached source %s: %w", source.Name, err)
+	}
+	if err := os.Rename(cloneDir, dir); err != nil {
+		return fmt.Errorf("failed to cache source %s: %w", source.Name, err)
+	}
+
+	return nil
+}
+
+func (sm *SourceManager) sourceDir(name string) string {
+	return filepath.Join(sm.cacheDir, name)
+}
+
+func (sm *SourceManager) readLock() (*TemplateLock, error) {
+	data, err := os.ReadFile(sm.lockPath)
+	if os.IsNotExist(err) {
+		return &TemplateLock{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile %s: %w", sm.lockPath, err)
+	}
+
+	var lock TemplateLock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile %s: %w", sm.lockPath, err)
+	}
+	return &lock, nil
+}
+
+func (sm *SourceManager) writeLock(lock *TemplateLock) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lockfile: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(sm.lockPath), 0755); err != nil {
+		return fmt.Errorf("failed to create lockfile directory: %w", err)
+	}
+
+	if err := os.WriteFile(sm.lockPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	return nil
+}
+
+// architecturesRoot returns the directory holding the architectures of a
+// source: its architectures/ subdirectory if present, else the repo root
+func architecturesRoot(repoDir string) string {
+	dir := filepath.Join(repoDir, "architectures")
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return dir
+	}
+	return repoDir
+}
+
+func hasArchitectures(root string) bool {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return false
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, entry.Name(), ArchitectureManifestFile)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSourceSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		url  string
+		ref  string
+	}{
+		{"https://git.internal/acme/gomake-templates.git@v2", "https://git.internal/acme/gomake-templates.git", "v2"},
+		{"https://git.internal/acme/gomake-templates.git", "https://git.internal/acme/gomake-templates.git", ""},
+		{"https://user@git.internal/acme/templates.git", "https://user@git.internal/acme/templates.git", ""},
+		{"git@github.com:acme/templates.git", "git@github.com:acme/templates.git", ""},
+		{"git@github.com:acme/templates.git@main", "git@github.com:acme/templates.git", "main"},
+		{"file:///srv/templates.git@v1", "file:///srv/templates.git", "v1"},
+		{"/srv/templates.git", "/srv/templates.git", ""},
+	}
+
+	for _, tt := range tests {
+		url, ref := ParseSourceSpec(tt.spec)
+		if url != tt.url || ref != tt.ref {
+			t.Errorf("ParseSourceSpec(%q) = %q, %q; want %q, %q", tt.spec, url, ref, tt.url, tt.ref)
+		}
+	}
+}
+
+func TestSourceManagerAdd(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	work := filepath.Join(root, "work")
+	bare := filepath.Join(root, "templates.git")
+
+	git := func(dir string, args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		if err := runGit(dir, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	writeTestFile(t, filepath.Join(work, "architectures", "service", ArchitectureManifestFile), "description: House service\n", 0644)
+	writeTestFile(t, filepath.Join(work, "architectures", "service", "main.go.tmpl"), "package main // v1\n", 0644)
+	git(work, "init", "--quiet")
+	git(work, "add", ".")
+	git(work, "commit", "--quiet", "-m", "v1")
+	git(work, "tag", "v1")
+	writeTestFile(t, filepath.Join(work, "architectures", "service", "main.go.tmpl"), "package main // v2\n", 0644)
+	git(work, "commit", "--quiet", "-am", "v2")
+	git(root, "clone", "--quiet", "--bare", work, bare)
+
+	sm := NewSourceManager(filepath.Join(root, "cache"), filepath.Join(root, "templates.lock"))
+
+	source, err := sm.Add("acme", "file://"+bare, "v1", false)
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	tagged, err := gitOutput(work, "rev-parse", "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source.Commit != tagged {
+		t.Errorf("pinned commit = %s, want %s", source.Commit, tagged)
+	}
+
+	if _, err := sm.Add("acme", "file://"+bare, "", false); err == nil {
+		t.Error("Add() of an existing source without force succeeded")
+	}
+
+	// A fresh manager fetches nothing when loading, but restores the
+	// pinned commit from the lockfile alone, not the latest commit of the
+	// remote
+	sm = NewSourceManager(filepath.Join(root, "cache2"), filepath.Join(root, "templates.lock"))
+	if err := sm.LoadArchitectures(); err == nil || !strings.Contains(err.Error(), "acme") {
+		t.Errorf("LoadArchitectures() of a missing source error = %v", err)
+	}
+	if _, ok := LookupArchitecture("acme/service"); ok {
+		t.Fatal("architecture acme/service registered without its source")
+	}
+	if restored, err := sm.Restore(); err != nil || len(restored) != 1 {
+		t.Fatalf("Restore() = %v, %v", restored, err)
+	}
+	if err := sm.LoadArchitectures(); err != nil {
+		t.Fatalf("LoadArchitectures() error = %v", err)
+	}
+	t.Cleanup(func() { unregisterArchitecture("acme/service") })
+
+	spec, ok := LookupArchitecture("acme/service")
+	if !ok {
+		t.Fatal("architecture acme/service not registered")
+	}
+
+	files, err := spec.New().Files(&Config{ProjectName: "svc", Architecture: "acme/service"})
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "main.go" || files[0].Text != "package main // v1\n" {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+}
+
+func TestSourceManagerAddDashURL(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	// A URL starting with "-" is a repository, not a git option
+	root := t.TempDir()
+	repo := filepath.Join(root, "-templates")
+	writeTestFile(t, filepath.Join(repo, "architectures", "service", ArchitectureManifestFile), "description: House service\n", 0644)
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "v1"},
+	} {
+		if err := runGit(repo, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	sm := NewSourceManager(filepath.Join(root, "cache"), filepath.Join(root, "templates.lock"))
+	if _, err := sm.Add("acme", "-templates", "", false); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+}
@@ -3,8 +3,11 @@ package generator
 import (
 	"embed"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"text/template"
 )
@@ -12,27 +15,100 @@ import (
 //go:embed templates/*
 var templatesFS embed.FS
 
-// TemplateManager manages template loading and rendering
+// EmbeddedLayer is the name of the built-in template layer
+const EmbeddedLayer = "embedded"
+
+// TemplateLayer is a directory of templates that shadow the embedded ones
+// by name, e.g. <dir>/common/logger.tmpl replaces common/logger
+type TemplateLayer struct {
+	Name string
+	Dir  string
+}
+
+// TemplateLayers returns the override layers in order of precedence: the
+// .gomake/templates directory in dir, then the user config dir. dir is the
+// directory new projects are generated in, or the root of an existing
+// project. Without a dir there is no project layer.
+func TemplateLayers(dir string) []TemplateLayer {
+	var layers []TemplateLayer
+	if dir != "" {
+		layers = append(layers, TemplateLayer{Name: "project", Dir: filepath.Join(dir, ".gomake", "templates")})
+	}
+	return append(layers, TemplateLayer{Name: "user", Dir: filepath.Join(os.Getenv("HOME"), ".config", "gomake", "templates")})
+}
+
+// TemplateManager manages template loading and rendering. All templates
+// are parsed into one set, so a template can call any other one as a
+// partial with {{template "common/graceful_shutdown" .}}. A template
+// starting with {{/* extends "common/main" */}} renders its base instead,
+// with the {{block}}s redefined by its own {{define}}s.
 type TemplateManager struct {
-	templates map[string]*template.Template
+	root    *template.Template
+	targets map[string]renderTarget
+	texts   map[string]string
+	origins map[string]TemplateOrigin
+}
+
+// renderTarget is the template set and entry point a template renders with
+type renderTarget struct {
+	set  *template.Template
+	name string
+}
+
+// extendsRegex matches the extends directive on the first line of a template
+var extendsRegex = regexp.MustCompile(`^\{\{-?\s*/\*\s*extends\s+"([^"]+)"\s*\*/\s*-?\}\}`)
+
+// TemplateOrigin records which layer and file a template was loaded from
+type TemplateOrigin struct {
+	Layer  string
+	Source string
 }
 
-// NewTemplateManager creates a new template manager
-func NewTemplateManager() (*TemplateManager, error) {
+// NewTemplateManager creates a new template manager with the embedded
+// templates overridden by the TemplateLayers of dir
+func NewTemplateManager(dir string) (*TemplateManager, error) {
+	return NewTemplateManagerWithLayers(TemplateLayers(dir))
+}
+
+// NewTemplateManagerWithLayers creates a new template manager with the
+// embedded templates overridden by layers, the first layer winning
+func NewTemplateManagerWithLayers(layers []TemplateLayer) (*TemplateManager, error) {
 	tm := &TemplateManager{
-		templates: make(map[string]*template.Template),
+		targets: make(map[string]renderTarget),
+		texts:   make(map[string]string),
+		origins: make(map[string]TemplateOrigin),
 	}
 
-	if err := tm.loadTemplates(); err != nil {
+	embedded, err := fs.Sub(templatesFS, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+	if err := tm.loadTemplates(embedded, EmbeddedLayer, "templates"); err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+
+	// Load the lowest precedence layer first so higher ones replace it
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := layers[i]
+		if info, err := os.Stat(layer.Dir); err != nil || !info.IsDir() {
+			continue
+		}
+		if err := tm.loadTemplates(os.DirFS(layer.Dir), layer.Name, layer.Dir); err != nil {
+			return nil, fmt.Errorf("failed to load %s templates from %s: %w", layer.Name, layer.Dir, err)
+		}
+	}
+
+	if err := tm.parseTemplates(); err != nil {
 		return nil, fmt.Errorf("failed to load templates: %w", err)
 	}
 
 	return tm, nil
 }
 
-// loadTemplates loads all templates from embedded filesystem
-func (tm *TemplateManager) loadTemplates() error {
-	return filepath.WalkDir("templates", func(path string, d os.DirEntry, err error) error {
+// loadTemplates reads all templates from fsys, replacing templates of the
+// same name. Names are paths relative to fsys without the .tmpl suffix.
+func (tm *TemplateManager) loadTemplates(fsys fs.FS, layer, root string) error {
+	return fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -41,54 +117,180 @@ func (tm *TemplateManager) loadTemplates() error {
 			return nil
 		}
 
-		content, err := templatesFS.ReadFile(path)
+		content, err := fs.ReadFile(fsys, path)
 		if err != nil {
 			return fmt.Errorf("failed to read template %s: %w", path, err)
 		}
 
-		// Create template name from path (remove templates/ prefix and .tmpl suffix)
-		templateName := strings.TrimPrefix(path, "templates/")
-		templateName = strings.TrimSuffix(templateName, ".tmpl")
+		templateName := strings.TrimSuffix(path, ".tmpl")
+		source := filepath.Join(root, filepath.FromSlash(path))
 
-		tmpl, err := template.New(templateName).Parse(string(content))
-		if err != nil {
-			return fmt.Errorf("failed to parse template %s: %w", path, err)
-		}
-
-		tm.templates[templateName] = tmpl
+		tm.texts[templateName] = string(content)
+		tm.origins[templateName] = TemplateOrigin{Layer: layer, Source: source}
 		return nil
 	})
 }
 
+// parseTemplates parses every template without a base into the shared
+// set, then every extending template into a copy of its base's set
+func (tm *TemplateManager) parseTemplates() error {
+	tm.root = template.New("").Funcs(templateFuncs(tm.RenderTemplate))
+
+	names := tm.ListTemplates()
+	for _, name := range names {
+		if extendsDirective(tm.texts[name]) != "" {
+			continue
+		}
+		if _, err := tm.root.New(name).Parse(tm.texts[name]); err != nil {
+			return fmt.Errorf("failed to parse template %s: %w", tm.origins[name].Source, err)
+		}
+		tm.targets[name] = renderTarget{set: tm.root, name: name}
+	}
+
+	for _, name := range names {
+		if _, err := tm.resolve(name, make(map[string]bool)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolve returns the render target of a template, parsing extending
+// templates and their bases on first use
+func (tm *TemplateManager) resolve(name string, visiting map[string]bool) (renderTarget, error) {
+	if target, ok := tm.targets[name]; ok {
+		return target, nil
+	}
+
+	text, exists := tm.texts[name]
+	if !exists {
+		return renderTarget{}, fmt.Errorf("template %s not found", name)
+	}
+	if visiting[name] {
+		return renderTarget{}, fmt.Errorf("template %s extends itself", name)
+	}
+	visiting[name] = true
+
+	base, err := tm.resolve(extendsDirective(text), visiting)
+	if err != nil {
+		return renderTarget{}, fmt.Errorf("failed to extend template %s: %w", name, err)
+	}
+
+	set, err := base.set.Clone()
+	if err != nil {
+		return renderTarget{}, fmt.Errorf("failed to extend template %s: %w", name, err)
+	}
+	if _, err := set.New(name).Parse(text); err != nil {
+		return renderTarget{}, fmt.Errorf("failed to parse template %s: %w", tm.origins[name].Source, err)
+	}
+
+	target := renderTarget{set: set, name: base.name}
+	tm.targets[name] = target
+	return target, nil
+}
+
 // RenderTemplate renders a template with given data
 func (tm *TemplateManager) RenderTemplate(templateName string, data interface{}) (string, error) {
-	tmpl, exists := tm.templates[templateName]
+	target, exists := tm.targets[templateName]
 	if !exists {
 		return "", fmt.Errorf("template %s not found", templateName)
 	}
 
 	var buf strings.Builder
-	if err := tmpl.Execute(&buf, data); err != nil {
+	if err := target.set.ExecuteTemplate(&buf, target.name, data); err != nil {
 		return "", fmt.Errorf("failed to execute template %s: %w", templateName, err)
 	}
 
 	return buf.String(), nil
 }
 
-// GetTemplate returns a template by name
+// RenderText renders text that is not part of the template set, e.g. a
+// custom template file, with access to every template in the set. The
+// text may extend a template of the set.
+func (tm *TemplateManager) RenderText(name, text string, data interface{}) (string, error) {
+	target := renderTarget{set: tm.root, name: name}
+	if base := extendsDirective(text); base != "" {
+		extended, exists := tm.targets[base]
+		if !exists {
+			return "", fmt.Errorf("template %s extends unknown template %s", name, base)
+		}
+		target = extended
+	}
+
+	set, err := target.set.Clone()
+	if err != nil {
+		return "", err
+	}
+	if _, err := set.New(name).Parse(text); err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	if err := set.ExecuteTemplate(&buf, target.name, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderString renders text such as a templated path, where include
+// renders templates of the set
+func (tm *TemplateManager) renderString(name, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs(tm.RenderTemplate)).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// GetTemplate returns a template by name. For an extending template this
+// is its base with the overridden blocks.
 func (tm *TemplateManager) GetTemplate(templateName string) (*template.Template, error) {
-	tmpl, exists := tm.templates[templateName]
+	target, exists := tm.targets[templateName]
 	if !exists {
 		return nil, fmt.Errorf("template %s not found", templateName)
 	}
-	return tmpl, nil
+	return target.set.Lookup(target.name), nil
+}
+
+// RawTemplate returns the unrendered text of a template
+func (tm *TemplateManager) RawTemplate(templateName string) (string, error) {
+	text, exists := tm.texts[templateName]
+	if !exists {
+		return "", fmt.Errorf("template %s not found", templateName)
+	}
+	return text, nil
+}
+
+// Origin returns the layer and file a template was loaded from
+func (tm *TemplateManager) Origin(templateName string) (TemplateOrigin, error) {
+	origin, exists := tm.origins[templateName]
+	if !exists {
+		return TemplateOrigin{}, fmt.Errorf("template %s not found", templateName)
+	}
+	return origin, nil
 }
 
-// ListTemplates returns all available template names
+// ListTemplates returns all available template names in sorted order
 func (tm *TemplateManager) ListTemplates() []string {
 	var names []string
-	for name := range tm.templates {
+	for name := range tm.texts {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
+
+// extendsDirective returns the base named by the extends directive of a
+// template, or "" if it has none
+func extendsDirective(text string) string {
+	if match := extendsRegex.FindStringSubmatch(strings.TrimLeft(text, " \t\r\n")); match != nil {
+		return match[1]
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplateInheritance(t *testing.T) {
+	layer := t.TempDir()
+	writeTestFile(t, filepath.Join(layer, "acme", "main.go.tmpl"),
+		"{{/* extends \"common/main\" */}}\n{{define \"banner\"}}acme {{.ProjectName}}{{end}}\n", 0644)
+	writeTestFile(t, filepath.Join(layer, "acme", "child.go.tmpl"),
+		"{{/* extends \"acme/main.go\" */}}\n{{define \"shutdown\"}}\n\t// child\n{{end}}\n", 0644)
+
+	tm, err := NewTemplateManagerWithLayers([]TemplateLayer{{Name: "test", Dir: layer}})
+	if err != nil {
+		t.Fatalf("NewTemplateManagerWithLayers() error = %v", err)
+	}
+
+	data := NewTemplateData(&Config{ProjectName: "shop", Architecture: "basic"})
+
+	main, err := tm.RenderTemplate("acme/main.go", data)
+	if err != nil {
+		t.Fatalf("RenderTemplate() error = %v", err)
+	}
+	for _, want := range []string{"package main", `appLogger.Info("Starting acme shop")`, "signal.Notify", "Shutting down application"} {
+		if !strings.Contains(main, want) {
+			t.Errorf("acme/main.go does not contain %q:\n%s", want, main)
+		}
+	}
+
+	child, err := tm.RenderTemplate("acme/child.go", data)
+	if err != nil {
+		t.Fatalf("RenderTemplate() error = %v", err)
+	}
+	if !strings.Contains(child, "Starting acme shop") || !strings.Contains(child, "// child") || strings.Contains(child, "Shutting down") {
+		t.Errorf("unexpected acme/child.go:\n%s", child)
+	}
+
+	// Overriding blocks must not leak into the base or other architectures
+	base, err := tm.RenderTemplate("common/main", data)
+	if err != nil {
+		t.Fatalf("RenderTemplate() error = %v", err)
+	}
+	if !strings.Contains(base, "Starting shop application") {
+		t.Errorf("common/main picked up an override:\n%s", base)
+	}
+
+	partial, err := tm.RenderText("custom.go", "{{template \"common/graceful_shutdown\" .}}", data)
+	if err != nil {
+		t.Fatalf("RenderText() error = %v", err)
+	}
+	if !strings.HasPrefix(partial, "\t// Wait for interrupt signal") {
+		t.Errorf("unexpected partial:\n%s", partial)
+	}
+}
+
+func TestTemplateInheritanceCycle(t *testing.T) {
+	layer := t.TempDir()
+	writeTestFile(t, filepath.Join(layer, "a.tmpl"), "{{/* extends \"b\" */}}", 0644)
+	writeTestFile(t, filepath.Join(layer, "b.tmpl"), "{{/* extends \"a\" */}}", 0644)
+
+	if _, err := NewTemplateManagerWithLayers([]TemplateLayer{{Name: "test", Dir: layer}}); err == nil {
+		t.Fatal("NewTemplateManagerWithLayers() with an extends cycle succeeded")
+	}
+}
@@ -0,0 +1,257 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gomake/internal/diff"
+)
+
+// UpgradeStatus is what gomake upgrade did with a file
+type UpgradeStatus string
+
+const (
+	// UpgradeUnchanged means the file already has the new content
+	UpgradeUnchanged UpgradeStatus = "unchanged"
+	// UpgradeUpdated means an unmodified file was replaced by the new render
+	UpgradeUpdated UpgradeStatus = "updated"
+	// UpgradeAdded means the templates generate a new file
+	UpgradeAdded UpgradeStatus = "added"
+	// UpgradeKept means the template didn't change and the local
+	// modifications were kept
+	UpgradeKept UpgradeStatus = "kept"
+	// UpgradeMerged means template changes were merged cleanly into a
+	// modified file
+	UpgradeMerged UpgradeStatus = "merged"
+	// UpgradeConflict means template changes and local modifications
+	// overlap
+	UpgradeConflict UpgradeStatus = "conflict"
+	// UpgradeSkipped means the file was deleted locally and stays deleted
+	UpgradeSkipped UpgradeStatus = "skipped"
+)
+
+// UpgradeResult is a file handled by an upgrade, with a slash-separated
+// path relative to the project
+type UpgradeResult struct {
+	Path   string
+	Status UpgradeStatus
+	// Conflicts is the number of conflicting regions of a conflict
+	Conflicts int
+	// Reject is the .rej file holding the template changes of a conflict
+	// when they were not merged into the file
+	Reject string
+}
+
+// UpgradeOptions control how an upgrade handles the project
+type UpgradeOptions struct {
+	// DryRun reports what would change without writing anything
+	DryRun bool
+	// Reject leaves conflicting files untouched and writes the template
+	// changes to <file>.rej instead of conflict markers
+	Reject bool
+}
+
+// Upgrader re-renders a generated project with the current templates and
+// merges the changes into it. The original render kept with the manifest
+// is the base of a three-way merge between the files on disk and the new
+// render, so local modifications survive template updates.
+type Upgrader struct {
+	config *Config
+	logger Logger
+}
+
+// NewUpgrader creates an upgrader for the project described by config,
+// usually read from its manifest with DetectProject
+func NewUpgrader(config *Config, logger Logger) *Upgrader {
+	return &Upgrader{config: config, logger: logger}
+}
+
+// upgradeChange is a planned write of an upgrade
+type upgradeChange struct {
+	path string
+	data []byte
+	mode os.FileMode
+}
+
+// Upgrade upgrades the project in projectPath and returns the results
+// sorted by path
+func (u *Upgrader) Upgrade(projectPath string, options UpgradeOptions) ([]UpgradeResult, error) {
+	current, err := ReadProjectManifest(projectPath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s has no %s; only projects generated with a manifest can be upgraded", projectPath, ProjectManifestFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	u.logger.Info("Rendering project", "version", Version, "generated_with", current.Version)
+	render, root, err := u.render(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestFile, ok := render.File(filepath.Join(root, filepath.FromSlash(ProjectManifestFile)))
+	if !ok {
+		return nil, fmt.Errorf("failed to render manifest")
+	}
+	manifest, err := parseProjectManifest(ProjectManifestFile, manifestFile.Data)
+	if err != nil {
+		return nil, err
+	}
+	manifest.GeneratedAt = current.GeneratedAt
+
+	labels := diff.Labels{Ours: "current", Theirs: "gomake " + Version}
+	var results []UpgradeResult
+	var changes []upgradeChange
+
+	for _, file := range manifest.Files {
+		rendered, _ := render.File(filepath.Join(root, filepath.FromSlash(file.Path)))
+		target := filepath.Join(projectPath, filepath.FromSlash(file.Path))
+		old, recorded := current.File(file.Path)
+
+		result, change, err := upgradeFile(projectPath, target, old, recorded, rendered, options, labels)
+		if err != nil {
+			return nil, err
+		}
+		result.Path = file.Path
+		results = append(results, result)
+		changes = append(changes, change...)
+	}
+
+	// Files the templates no longer render, such as generated components,
+	// stay recorded as they were
+	for _, file := range current.Files {
+		if _, ok := manifest.File(file.Path); !ok {
+			manifest.Files = append(manifest.Files, file)
+		}
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+
+	if options.DryRun {
+		return results, nil
+	}
+
+	writer := NewDiskWriter()
+	for _, change := range changes {
+		if err := writer.WriteFile(change.path, change.data, change.mode, "upgrade"); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", change.path, err)
+		}
+	}
+	if err := u.writeBase(writer, render, root, projectPath, manifest); err != nil {
+		return nil, err
+	}
+	if err := manifest.Write(writer, projectPath); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// render renders the project in projectPath in memory and returns the
+// writer holding it and the path of the project inside it
+func (u *Upgrader) render(projectPath string) (*MemoryWriter, string, error) {
+	config := *u.config
+	writer := NewMemoryWriter()
+	if err := renderProject(&config, writer, projectPath); err != nil {
+		return nil, "", err
+	}
+	return writer, projectPath, nil
+}
+
+// upgradeFile decides what happens to one rendered file. recorded tells
+// whether the file is in the current manifest as old.
+func upgradeFile(projectPath, target string, old ManifestFile, recorded bool, rendered *MemoryFile, options UpgradeOptions, labels diff.Labels) (UpgradeResult, []upgradeChange, error) {
+	data, err := os.ReadFile(target)
+	if err != nil && !os.IsNotExist(err) {
+		return UpgradeResult{}, nil, fmt.Errorf("failed to read %s: %w", target, err)
+	}
+	exists := err == nil
+
+	switch {
+	case !exists && recorded:
+		return UpgradeResult{Status: UpgradeSkipped}, nil, nil
+	case !exists:
+		return UpgradeResult{Status: UpgradeAdded}, []upgradeChange{{target, rendered.Data, rendered.Mode}}, nil
+	case bytes.Equal(data, rendered.Data):
+		return UpgradeResult{Status: UpgradeUnchanged}, nil, nil
+	}
+
+	mode := rendered.Mode
+	if info, err := os.Stat(target); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if recorded && checksum(data) == old.Hash {
+		return UpgradeResult{Status: UpgradeUpdated}, []upgradeChange{{target, rendered.Data, mode}}, nil
+	}
+	if recorded && checksum(rendered.Data) == old.Hash {
+		return UpgradeResult{Status: UpgradeKept}, nil, nil
+	}
+
+	// Merge against the original render; without one, e.g. for a file the
+	// templates didn't generate before, the whole file conflicts
+	var base []byte
+	if recorded {
+		base, err = ReadBase(projectPath, old)
+		if err != nil && !os.IsNotExist(err) {
+			return UpgradeResult{}, nil, fmt.Errorf("failed to read original render of %s: %w", old.Path, err)
+		}
+	}
+
+	merged := diff.Merge(string(base), string(data), string(rendered.Data), labels)
+	if merged.Conflicts == 0 {
+		return UpgradeResult{Status: UpgradeMerged}, []upgradeChange{{target, []byte(merged.Text), mode}}, nil
+	}
+
+	result := UpgradeResult{Status: UpgradeConflict, Conflicts: merged.Conflicts}
+	if !options.Reject {
+		return result, []upgradeChange{{target, []byte(merged.Text), mode}}, nil
+	}
+
+	rel, _ := filepath.Rel(projectPath, target)
+	rel = filepath.ToSlash(rel)
+	reject := diff.Unified("a/"+rel, "b/"+rel, string(base), string(rendered.Data), 3)
+	result.Reject = rel + ".rej"
+	return result, []upgradeChange{{target + ".rej", []byte(reject), 0644}}, nil
+}
+
+// writeBase writes the original render of the upgraded files and removes
+// the ones no longer recorded in manifest
+func (u *Upgrader) writeBase(writer Writer, render *MemoryWriter, root, projectPath string, manifest *ProjectManifest) error {
+	renderedBase := filepath.Join(root, filepath.FromSlash(ProjectBaseDir)) + string(filepath.Separator)
+	for _, file := range render.Files() {
+		if !strings.HasPrefix(file.Path, renderedBase) {
+			continue
+		}
+		hash := strings.TrimPrefix(file.Path, renderedBase)
+		if err := writer.WriteFile(baseObjectPath(projectPath, hash), file.Data, 0644, "base"); err != nil {
+			return fmt.Errorf("failed to write original render: %w", err)
+		}
+	}
+
+	referenced := make(map[string]bool)
+	for _, file := range manifest.Files {
+		referenced[file.Hash] = true
+	}
+
+	baseDir := filepath.Join(projectPath, filepath.FromSlash(ProjectBaseDir))
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", baseDir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || referenced[entry.Name()] {
+			continue
+		}
+		u.logger.Debug("Removing original render", "sha256", entry.Name())
+		if err := os.Remove(filepath.Join(baseDir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
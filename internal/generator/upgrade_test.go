@@ -0,0 +1,234 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// upgradeFixture is a generated project whose manifest is rewritten to
+// look generated by older templates
+type upgradeFixture struct {
+	t           *testing.T
+	projectPath string
+	manifest    *ProjectManifest
+}
+
+func newUpgradeFixture(t *testing.T) *upgradeFixture {
+	projectPath := generateOnDisk(t, &Config{
+		ProjectName:  "orders",
+		Architecture: "basic",
+		WithMakefile: true,
+		License:      "MIT",
+	})
+	manifest, err := ReadProjectManifest(projectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &upgradeFixture{t: t, projectPath: projectPath, manifest: manifest}
+}
+
+func (f *upgradeFixture) read(path string) string {
+	data, err := os.ReadFile(filepath.Join(f.projectPath, filepath.FromSlash(path)))
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return string(data)
+}
+
+func (f *upgradeFixture) write(path, content string) {
+	if err := os.WriteFile(filepath.Join(f.projectPath, filepath.FromSlash(path)), []byte(content), 0644); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+// generatedAs records old as the original render of path
+func (f *upgradeFixture) generatedAs(path, old string) {
+	for i := range f.manifest.Files {
+		if f.manifest.Files[i].Path == path {
+			f.manifest.Files[i].Hash = checksum([]byte(old))
+			if err := os.WriteFile(baseObjectPath(f.projectPath, f.manifest.Files[i].Hash), []byte(old), 0644); err != nil {
+				f.t.Fatal(err)
+			}
+			return
+		}
+	}
+	f.t.Fatalf("%s is not recorded", path)
+}
+
+func (f *upgradeFixture) upgrade(options UpgradeOptions) map[string]UpgradeResult {
+	if err := f.manifest.Write(NewDiskWriter(), f.projectPath); err != nil {
+		f.t.Fatal(err)
+	}
+
+	log := quietLogger(f.t)
+	config, err := DetectProject(f.projectPath, "")
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	results, err := NewUpgrader(config, log).Upgrade(f.projectPath, options)
+	if err != nil {
+		f.t.Fatalf("Upgrade failed: %v", err)
+	}
+
+	byPath := make(map[string]UpgradeResult)
+	for _, result := range results {
+		byPath[result.Path] = result
+	}
+	return byPath
+}
+
+// replaceLine replaces the first line of text holding old
+func replaceLine(text, old, new string) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		if strings.Contains(line, old) {
+			lines[i] = new + "\n"
+			break
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+func TestUpgrade(t *testing.T) {
+	f := newUpgradeFixture(t)
+	readme, makefile, gitignore := f.read("README.md"), f.read("Makefile"), f.read(".gitignore")
+
+	// Unmodified file whose template changed
+	f.generatedAs("go.mod", "module orders\n\ngo 1.19\n")
+	f.write("go.mod", "module orders\n\ngo 1.19\n")
+
+	// Local edit and template change in separate places
+	oldReadme := replaceLine(readme, "# orders", "# Orders (old)")
+	f.generatedAs("README.md", oldReadme)
+	f.write("README.md", oldReadme+"\nDeployed by the payments team.\n")
+
+	// Local edit and template change on the same line
+	oldMakefile := replaceLine(makefile, "build:", "build: old")
+	f.generatedAs("Makefile", oldMakefile)
+	f.write("Makefile", replaceLine(makefile, "build:", "build: mine"))
+
+	// Local edit of a file whose template didn't change
+	f.write(".gitignore", gitignore+"tmp/\n")
+
+	// Deleted locally
+	if err := os.Remove(filepath.Join(f.projectPath, "LICENSE")); err != nil {
+		t.Fatal(err)
+	}
+
+	// New in the templates
+	if err := os.Remove(filepath.Join(f.projectPath, ".env")); err != nil {
+		t.Fatal(err)
+	}
+	var files []ManifestFile
+	for _, file := range f.manifest.Files {
+		if file.Path != ".env" {
+			files = append(files, file)
+		}
+	}
+	f.manifest.Files = files
+
+	results := f.upgrade(UpgradeOptions{})
+
+	want := map[string]UpgradeStatus{
+		"go.mod":             UpgradeUpdated,
+		"README.md":          UpgradeMerged,
+		"Makefile":           UpgradeConflict,
+		".gitignore":         UpgradeKept,
+		"LICENSE":            UpgradeSkipped,
+		".env":               UpgradeAdded,
+		"cmd/orders/main.go": UpgradeUnchanged,
+	}
+	for path, status := range want {
+		if results[path].Status != status {
+			t.Errorf("%s: status = %q, want %q", path, results[path].Status, status)
+		}
+	}
+
+	if got := f.read("README.md"); got != readme+"\nDeployed by the payments team.\n" {
+		t.Errorf("merged README.md =\n%s", got)
+	}
+	if got := f.read("Makefile"); !strings.Contains(got, "<<<<<<< current\nbuild: mine\n=======\n") {
+		t.Errorf("conflicting Makefile has no conflict markers:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(f.projectPath, "LICENSE")); !os.IsNotExist(err) {
+		t.Error("LICENSE deleted locally was restored")
+	}
+
+	// The manifest now records the new render
+	manifest, err := ReadProjectManifest(f.projectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !manifest.GeneratedAt.Equal(f.manifest.GeneratedAt) {
+		t.Errorf("GeneratedAt = %v, want the original %v", manifest.GeneratedAt, f.manifest.GeneratedAt)
+	}
+	for _, path := range []string{"go.mod", "README.md", ".env"} {
+		file, _ := manifest.File(path)
+		base, err := ReadBase(f.projectPath, file)
+		if err != nil {
+			t.Errorf("%s: original render not kept: %v", path, err)
+			continue
+		}
+		if modified, _ := manifest.Modified(f.projectPath, file); path != "README.md" && modified {
+			t.Errorf("%s: recorded render %q doesn't match the file", path, base)
+		}
+	}
+	if _, err := os.Stat(baseObjectPath(f.projectPath, checksum([]byte(oldReadme)))); !os.IsNotExist(err) {
+		t.Error("original render of the old README.md was not removed")
+	}
+
+	// Upgrading again changes nothing
+	f.manifest = manifest
+	for path, result := range f.upgrade(UpgradeOptions{}) {
+		if result.Status == UpgradeUpdated || result.Status == UpgradeMerged || result.Status == UpgradeAdded {
+			t.Errorf("%s: second upgrade %s it", path, result.Status)
+		}
+	}
+}
+
+func TestUpgradeReject(t *testing.T) {
+	f := newUpgradeFixture(t)
+	makefile := f.read("Makefile")
+
+	f.generatedAs("Makefile", replaceLine(makefile, "build:", "build: old"))
+	local := replaceLine(makefile, "build:", "build: mine")
+	f.write("Makefile", local)
+
+	results := f.upgrade(UpgradeOptions{Reject: true})
+
+	result := results["Makefile"]
+	if result.Status != UpgradeConflict || result.Reject != "Makefile.rej" {
+		t.Fatalf("Makefile: %+v, want a conflict with Makefile.rej", result)
+	}
+	if got := f.read("Makefile"); got != local {
+		t.Errorf("rejected Makefile was changed:\n%s", got)
+	}
+	if rej := f.read("Makefile.rej"); !strings.Contains(rej, "-build: old\n") || !strings.HasPrefix(rej, "--- a/Makefile\n") {
+		t.Errorf("Makefile.rej doesn't hold the template change:\n%s", rej)
+	}
+}
+
+func TestUpgradeDryRun(t *testing.T) {
+	f := newUpgradeFixture(t)
+	f.generatedAs("go.mod", "module orders\n")
+	f.write("go.mod", "module orders\n")
+
+	results := f.upgrade(UpgradeOptions{DryRun: true})
+	if results["go.mod"].Status != UpgradeUpdated {
+		t.Errorf("go.mod: status = %q, want %q", results["go.mod"].Status, UpgradeUpdated)
+	}
+	if got := f.read("go.mod"); got != "module orders\n" {
+		t.Errorf("dry run wrote go.mod:\n%s", got)
+	}
+}
+
+func TestUpgradeWithoutManifest(t *testing.T) {
+	log := quietLogger(t)
+
+	_, err := NewUpgrader(&Config{ProjectName: "orders", Architecture: "basic"}, log).Upgrade(t.TempDir(), UpgradeOptions{})
+	if err == nil || !strings.Contains(err.Error(), ProjectManifestFile) {
+		t.Errorf("Upgrade() error = %v, want missing manifest", err)
+	}
+}
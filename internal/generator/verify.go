@@ -0,0 +1,35 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Verify builds and vets a generated project offline. It returns an error
+// with the tool output when either step fails.
+func Verify(projectPath string) error {
+	steps := [][]string{
+		{"go", "build", "./..."},
+		{"go", "vet", "./..."},
+	}
+
+	for _, step := range steps {
+		cmd := exec.Command(step[0], step[1:]...)
+		cmd.Dir = projectPath
+		cmd.Env = append(os.Environ(),
+			"GOFLAGS=-mod=mod",
+			"GOPROXY=off",
+			"GOWORK=off",
+			"GOTOOLCHAIN=local",
+		)
+
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s failed: %w\n%s", strings.Join(step, " "), err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGeneratedProjectsBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go build of generated projects in short mode")
+	}
+
+	for _, arch := range goldenArchitectures {
+		t.Run(arch, func(t *testing.T) {
+			log := quietLogger(t)
+
+			config := &Config{
+				ProjectName:  "myapp",
+				ModulePath:   "github.com/acme/myapp",
+				Architecture: arch,
+				TargetDir:    t.TempDir(),
+				WithDocker:   true,
+				License:      "MIT",
+				AutoYes:      true,
+			}
+
+			gen, err := New(config, log)
+			if err != nil {
+				t.Fatalf("failed to create generator: %v", err)
+			}
+			if err := gen.Generate(); err != nil {
+				t.Fatalf("failed to generate project: %v", err)
+			}
+
+			if err := Verify(filepath.Join(config.TargetDir, config.ProjectName)); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
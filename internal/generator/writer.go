@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Writer is the sink all generators write project output through
+type Writer interface {
+	// MkdirAll creates a directory along with any missing parents
+	MkdirAll(path string) error
+	// WriteFile writes a file, creating parent directories as needed.
+	// Source names the template or generator the content came from.
+	WriteFile(path string, data []byte, perm os.FileMode, source string) error
+}
+
+// DiskWriter writes project output to the local filesystem
+type DiskWriter struct{}
+
+// NewDiskWriter creates a new disk writer
+func NewDiskWriter() *DiskWriter {
+	return &DiskWriter{}
+}
+
+// MkdirAll creates a directory on disk
+func (dw *DiskWriter) MkdirAll(path string) error {
+	return os.MkdirAll(path, 0755)
+}
+
+// WriteFile writes a file to disk
+func (dw *DiskWriter) WriteFile(path string, data []byte, perm os.FileMode, source string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(path), err)
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// writesToDisk reports whether writer ends up writing to the local
+// filesystem
+func writesToDisk(writer Writer) bool {
+	switch w := writer.(type) {
+	case *DiskWriter:
+		return true
+	case *NoClobberWriter:
+		return writesToDisk(w.writer)
+	}
+	return false
+}
+
+// stagedWriter holds project output in memory until commit passes it on
+// to the writer
+type stagedWriter struct {
+	*MemoryWriter
+	writer Writer
+}
+
+func newStagedWriter(writer Writer) *stagedWriter {
+	return &stagedWriter{MemoryWriter: NewMemoryWriter(), writer: writer}
+}
+
+// commit writes the directories and files held so far through the writer
+func (sw *stagedWriter) commit() error {
+	for _, dir := range sw.Dirs() {
+		if err := sw.writer.MkdirAll(dir); err != nil {
+			return err
+		}
+	}
+	for _, file := range sw.Files() {
+		if err := sw.writer.WriteFile(file.Path, file.Data, file.Mode, file.Source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MemoryFile is a file recorded by MemoryWriter
+type MemoryFile struct {
+	Path   string
+	Data   []byte
+	Mode   os.FileMode
+	Source string
+}
+
+// MemoryWriter records project output in memory without touching disk
+type MemoryWriter struct {
+	dirs  map[string]bool
+	files map[string]*MemoryFile
+}
+
+// NewMemoryWriter creates a new in-memory writer
+func NewMemoryWriter() *MemoryWriter {
+	return &MemoryWriter{
+		dirs:  make(map[string]bool),
+		files: make(map[string]*MemoryFile),
+	}
+}
+
+// MkdirAll records a directory
+func (mw *MemoryWriter) MkdirAll(path string) error {
+	mw.dirs[filepath.Clean(path)] = true
+	return nil
+}
+
+// WriteFile records a file, replacing any previous content
+func (mw *MemoryWriter) WriteFile(path string, data []byte, perm os.FileMode, source string) error {
+	path = filepath.Clean(path)
+	mw.dirs[filepath.Dir(path)] = true
+	mw.files[path] = &MemoryFile{
+		Path:   path,
+		Data:   append([]byte(nil), data...),
+		Mode:   perm,
+		Source: source,
+	}
+	return nil
+}
+
+// Dirs returns all recorded directories sorted by path
+func (mw *MemoryWriter) Dirs() []string {
+	dirs := make([]string, 0, len(mw.dirs))
+	for dir := range mw.dirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// Files returns all recorded files sorted by path
+func (mw *MemoryWriter) Files() []*MemoryFile {
+	files := make([]*MemoryFile, 0, len(mw.files))
+	for _, file := range mw.files {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+// File returns the recorded file at path, if any
+func (mw *MemoryWriter) File(path string) (*MemoryFile, bool) {
+	file, ok := mw.files[filepath.Clean(path)]
+	return file, ok
+}
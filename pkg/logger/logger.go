@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 
@@ -22,6 +23,11 @@ func New(verbose bool) *Logger {
 	}
 }
 
+// SetOutput redirects non-error messages, e.g. to keep stdout free for data
+func (l *Logger) SetOutput(w io.Writer) {
+	l.infoLog.SetOutput(w)
+}
+
 func (l *Logger) Info(msg string, args ...interface{}) {
 	if len(args) > 0 {
 		msg = fmt.Sprintf("%s: %v", msg, args)